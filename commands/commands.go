@@ -31,15 +31,116 @@ var commonFlags = []cli.Flag{
 		Name:  "aggregate, a",
 		Usage: "Aggregate all results into a single result",
 	},
+	cli.BoolFlag{
+		Name:  "aggregate-groups",
+		Usage: "Aggregate the results of each target group into one result per group",
+	},
+	cli.StringSliceFlag{
+		Name:  "tag",
+		Usage: "Only check targets with this tag, such as payments or tier=critical. Can be repeated or comma separated.",
+	},
+	cli.StringSliceFlag{
+		Name:  "exclude-tag",
+		Usage: "Skip targets with this tag. Can be repeated or comma separated.",
+	},
+	cli.StringSliceFlag{
+		Name:  "group",
+		Usage: "Only check targets in this group. Can be repeated or comma separated.",
+	},
 	cli.BoolFlag{
 		Name:  "no-headers",
 		Usage: "Quiet results into just the statuses. Usually useful in aggregate ",
 	},
 	cli.IntFlag{
 		Name:  "workers, w",
-		Usage: "Number of workers/goroutines to use to hit the sites",
+		Usage: "Number of workers/goroutines to use to hit the sites. Each worker checks one target at a time.",
+		Value: 10,
+	},
+	cli.StringFlag{
+		Name:  "history",
+		Usage: "Save every result to this history store, such as a directory or file:///var/lib/asrt. Empty = no history.",
+	},
+	cli.StringFlag{
+		Name:  "history-retention",
+		Usage: "Remove history older than this. 0 = keep everything. Format is Golang time.Duration.",
+		Value: "720h",
+	},
+	cli.IntFlag{
+		Name:  "host-concurrency",
+		Usage: "Maximum number of requests running against the same host at once. 0 = no limit.",
+	},
+	cli.Float64Flag{
+		Name:  "max-rps",
+		Usage: "Maximum number of requests started per second across all workers. 0 = no limit.",
+	},
+	cli.StringFlag{
+		Name:  "warn-after",
+		Usage: "Report a warning when a target takes longer than this to respond. Can be overridden per target with {O}warn-after=<duration>. 0 = never warn. Format is Golang time.Duration.",
+		Value: "0",
+	},
+	cli.StringFlag{
+		Name:  "fail-after",
+		Usage: "Report a failure when a target takes longer than this to respond. Can be overridden per target with {O}fail-after=<duration>. 0 = never fail. Format is Golang time.Duration.",
+		Value: "0",
+	},
+	cli.BoolFlag{
+		Name:  "strict-tls",
+		Usage: "Verify TLS certificates (trust chain, expiry and host name) instead of skipping verification. Can be enabled per target with {O}strict-tls.",
+	},
+	cli.IntFlag{
+		Name:  "cert-warn-days",
+		Usage: "Report a warning when a TLS certificate expires within this many days, and a failure once it has expired. Can be overridden per target with {O}cert-warn-days=<days>. 0 = no expiry check.",
+		Value: 0,
+	},
+	cli.StringFlag{
+		Name:  "ca-file",
+		Usage: "PEM bundle of certificate authorities used to verify targets. Setting this turns on certificate verification. Can be overridden per target with {O}ca-file=<path>.",
+	},
+	cli.StringFlag{
+		Name:  "cert-file",
+		Usage: "PEM client certificate for mutual TLS. Requires --key-file. Can be overridden per target with {O}cert-file=<path>.",
+	},
+	cli.StringFlag{
+		Name:  "key-file",
+		Usage: "PEM private key for the client certificate. Requires --cert-file. Can be overridden per target with {O}key-file=<path>.",
+	},
+	cli.StringFlag{
+		Name:  "server-name",
+		Usage: "Override the server name sent with SNI and used to verify the certificate. Can be overridden per target with {O}server-name=<name>.",
+	},
+	cli.StringFlag{
+		Name:  "proxy",
+		Usage: "Send requests through this proxy url instead of the one in the HTTP_PROXY/HTTPS_PROXY environment. Can be overridden per target with {O}proxy=<url>.",
+	},
+	cli.BoolFlag{
+		Name:  "fresh-connection",
+		Usage: "Open a new connection for every request instead of reusing kept-alive connections, to measure cold dns, connect and tls latency. Can be set per target with {O}fresh-connection.",
+	},
+	cli.IntFlag{
+		Name:  "attempts",
+		Usage: "Number of times to try a failing target before reporting it as failed. Can be overridden per target with {O}attempts=<n>.",
 		Value: 1,
 	},
+	cli.StringFlag{
+		Name:  "backoff",
+		Usage: "Wait the same retry-delay between attempts (fixed) or double it after every attempt (exponential). Can be overridden per target with {O}backoff=<fixed|exponential>.",
+		Value: "fixed",
+	},
+	cli.StringFlag{
+		Name:  "retry-delay",
+		Usage: "Time to wait after a failed attempt. Can be overridden per target with {O}retry-delay=<duration>. Format is Golang time.Duration.",
+		Value: "1s",
+	},
+	cli.StringFlag{
+		Name:  "retry-jitter",
+		Usage: "Add a random part of up to this much to every retry-delay. Can be overridden per target with {O}retry-jitter=<duration>. Format is Golang time.Duration.",
+		Value: "0",
+	},
+	cli.StringFlag{
+		Name:  "redirects",
+		Usage: "Redirect policy: follow, none or the maximum number of redirects to follow. Can be overridden per target with {O}redirects=<policy>.",
+		Value: "follow",
+	},
 	cli.StringFlag{
 		Name:  "method, m",
 		Usage: fmt.Sprint("Use HTTP Method for all URLs on command line. Does not affect file inputs. Valid values:", config.ValidMethods),
@@ -67,10 +168,39 @@ func getDashboardFlags() []cli.Flag {
 			Usage: "Rate between refreshes of statuses. Only effective for dashboard settings. 0 = no refresh. Format is Golang time.Duration.",
 			Value: "30s",
 		},
+		cli.StringFlag{
+			Name:  "jitter",
+			Usage: "Delay each check of a target by a random part of up to this duration, so that targets with the same rate do not all run at once. Targets can set their own rate and jitter with {O}interval and {O}jitter. Format is Golang time.Duration.",
+			Value: "0s",
+		},
+		cli.StringFlag{
+			Name:  "watch-interval",
+			Usage: "How often to check the target files for changes, and reload the targets when they changed. Targets are also reloaded on SIGHUP. 0 = only on SIGHUP. Format is Golang time.Duration.",
+			Value: "2s",
+		},
 		cli.BoolFlag{
 			Name:  "state-change-only, s",
 			Usage: "Only report on state changes. This is useful for long running jobs, especially when coupled with notification to slack or something similar.",
 		},
+		cli.IntFlag{
+			Name:  "down-after",
+			Usage: "Number of failed results in a row before a target that was up counts as down.",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name:  "up-after",
+			Usage: "Number of successful results in a row before a target that was down counts as up again.",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name:  "flap-changes",
+			Usage: "Report a target as flapping once its state changed more than this many times within flap-window. State changes of a flapping target are not reported until it settles. 0 = never.",
+		},
+		cli.StringFlag{
+			Name:  "flap-window",
+			Usage: "Time window for flap-changes. Format is Golang time.Duration.",
+			Value: "10m",
+		},
 	)
 }
 
@@ -83,6 +213,14 @@ func getServerFlags() []cli.Flag {
 		})
 }
 
+func getReportFlags() []cli.Flag {
+	return append(getBaseFlags(),
+		cli.StringFlag{
+			Name:  "slo",
+			Usage: "Availability objective for the error budget, such as 99.9. Can be overridden per target with {O}slo=<percent>. Empty = no error budget.",
+		})
+}
+
 func getRegisteredConfigurers() []cli.Flag {
 	var flags []cli.Flag
 	for _, c := range config.GetAllConfigurers() {
@@ -114,6 +252,20 @@ func GetCommands() []cli.Command {
 			Action:      cmdServer,
 			Flags:       getServerFlags(),
 		},
+		{
+			Name:        "report",
+			Usage:       "Print uptime, latency percentiles and error budgets from the history of the API list",
+			Description: "Argument is one or more URLs if a file is not provided. Needs --history.",
+			Action:      cmdReport,
+			Flags:       getReportFlags(),
+		},
+		{
+			Name:        "validate",
+			Usage:       "Check the API list for mistakes without making any requests",
+			Description: "Argument is one or more URLs if a file is not provided. Reports errors and warnings with their file and line, and exits with 1 on errors and 2 on warnings.",
+			Action:      cmdValidate,
+			Flags:       getBaseFlags(),
+		},
 	}
 }
 
@@ -22,33 +22,26 @@ func cmdDashboard(ctx *cli.Context) {
 	}
 
 	executor := execution.NewExecutor(c.AggregateOutput, c.FailuresOnly, c.StateChangeOnly, c.ResultFormatter(), c.Writer(), c.Workers)
-	printDashboard(c, executor)
+	executor.SetLimits(c.HostConcurrency, c.MaxRPS)
+	executor.SetStatePolicy(statePolicy(c))
+	useHistory(ctx, "dashboard", c, executor)
 	loopDashboard(c, executor)
 }
 
+// loopDashboard redraws the dashboard whenever a target was checked, showing the latest result of every target
 func loopDashboard(c *config.Configuration, executor *execution.Executor) {
-	done := make(chan struct{})
-	fn := func() {
-		close(done)
-	}
-
-	OsSignalShutdown(fn, 5)
-
-	ticker := time.NewTicker(c.Rate)
-
-	for {
-		select {
-		case <-ticker.C:
-			printDashboard(c, executor)
-		case <-done:
-			return
-		}
-	}
+	scheduleTargets(c, executor, execution.NewScheduler(executor, c.CurrentTargets()), func() {
+		clearAndWriteHeader(c)
+	})
 }
 
-func printDashboard(c *config.Configuration, executor *execution.Executor) {
-	clearAndWriteHeader(c)
-	executor.Execute(c.Targets)
+func statePolicy(c *config.Configuration) execution.StatePolicy {
+	return execution.StatePolicy{
+		FailuresToDown: c.DownAfter,
+		SuccessesToUp:  c.UpAfter,
+		FlapChanges:    c.FlapChanges,
+		FlapWindow:     c.FlapWindow,
+	}
 }
 
 func clearAndWriteHeader(c *config.Configuration) {
@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/codegangsta/cli"
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/execution"
+	"github.com/mkboudreau/asrt/storage"
+)
+
+// useHistory opens the history store, if there is one, and has the executor save every result to it
+func useHistory(ctx *cli.Context, command string, c *config.Configuration, executor *execution.Executor) storage.Store {
+	store, err := c.OpenHistory()
+	if err != nil {
+		cli.ShowCommandHelp(ctx, command)
+		fmt.Println("Could not open history. Reason:", err)
+		log.Fatalln("Exiting....")
+	}
+	if store != nil {
+		executor.SetStore(store)
+	}
+	return store
+}
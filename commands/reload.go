@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/execution"
+)
+
+// watchTargets returns a channel that receives when the process gets SIGHUP or, unless interval is 0, when
+// any of the target files changes. Reloads that pile up while the targets are being checked count as one.
+func watchTargets(files []string, interval time.Duration) <-chan struct{} {
+	reload := make(chan struct{}, 1)
+	trigger := func() {
+		select {
+		case reload <- struct{}{}:
+		default:
+		}
+	}
+
+	OsSignalReload(trigger)
+	if interval > 0 && len(files) > 0 {
+		go pollFiles(files, interval, trigger)
+	}
+	return reload
+}
+
+// fileVersion tells versions of a file apart. A file that does not exist has the zero version.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFiles(files []string) map[string]fileVersion {
+	versions := make(map[string]fileVersion)
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			versions[f] = fileVersion{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return versions
+}
+
+func pollFiles(files []string, interval time.Duration, changed func()) {
+	last := statFiles(files)
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		current := statFiles(files)
+		for _, f := range files {
+			if current[f] != last[f] {
+				log.Printf("Target file %v changed", f)
+				last = current
+				changed()
+				break
+			}
+		}
+	}
+}
+
+// reloadTargets keeps the current targets when the new ones cannot be read, and forgets the state of
+// targets that were removed or changed. CA bundles and client certificates are read again too.
+func reloadTargets(c *config.Configuration, executor *execution.Executor) {
+	stale, err := c.ReloadTargets()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not reload targets, keeping the current targets. Reason:", err)
+		return
+	}
+	executor.ForgetTargets(stale)
+	execution.ResetTransports()
+	log.Printf("Reloaded %d targets, %d removed or changed", len(c.CurrentTargets()), len(stale))
+}
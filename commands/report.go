@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+	"github.com/mkboudreau/asrt/report"
+	"github.com/mkboudreau/asrt/storage"
+	"github.com/mkboudreau/asrt/writer"
+)
+
+func cmdReport(ctx *cli.Context) {
+	c, err := config.GetConfiguration(ctx)
+	if err != nil {
+		cli.ShowCommandHelp(ctx, "report")
+		fmt.Println("Could not get configuration. Reason:", err)
+		log.Fatalln("Exiting....")
+	}
+
+	store, err := c.OpenHistory()
+	if err == nil && store == nil {
+		err = fmt.Errorf("--history is required")
+	}
+	if err != nil {
+		cli.ShowCommandHelp(ctx, "report")
+		fmt.Println("Could not open history. Reason:", err)
+		log.Fatalln("Exiting....")
+	}
+	defer store.Close()
+
+	results, err := reportResults(c.Targets, store, time.Now())
+	if err != nil {
+		fmt.Println("Could not read history. Reason:", err)
+		log.Fatalln("Exiting....")
+	}
+
+	os.Exit(writeReport(c.ResultFormatter(), c.Writer(), results))
+}
+
+func reportResults(targets []*config.Target, store storage.Store, now time.Time) ([]*output.Result, error) {
+	var results []*output.Result
+	for _, t := range targets {
+		records, err := store.Query(storage.Query{Url: t.URL, Label: t.Label, Since: now.Add(-report.Window)})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, report.Result(t, records, now))
+	}
+	return results, nil
+}
+
+// writeReport exits with 1 when any target used up its error budget
+func writeReport(formatter output.ResultFormatter, w io.Writer, results []*output.Result) int {
+	exitStatus := 0
+
+	writer.WriteToWriter(w, formatter.Header())
+	for i, r := range results {
+		if i > 0 {
+			writer.WriteToWriter(w, formatter.RecordSeparator())
+		}
+		writer.WriteToWriter(w, formatter.Reader(r))
+		if r.Report.ErrorBudget != nil && r.Report.ErrorBudget.Remaining < 0 {
+			exitStatus = 1
+		}
+	}
+	writer.WriteToWriter(w, formatter.Footer())
+	writer.DoneWithWriter(w)
+
+	return exitStatus
+}
@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/execution"
+)
+
+// scheduleTargets checks every target on its own interval until the process is shut down, and reloads the
+// targets when their files change or on SIGHUP. beforeReport is called before each report is written.
+func scheduleTargets(c *config.Configuration, executor *execution.Executor, scheduler *execution.Scheduler, beforeReport func()) {
+	done := make(chan struct{})
+	fn := func() {
+		close(done)
+	}
+
+	OsSignalShutdown(fn, 5)
+	reload := watchTargets(c.WatchedFiles(), c.WatchInterval)
+
+	scheduler.Run(done, reload, func() []*config.Target {
+		reloadTargets(c, executor)
+		return c.CurrentTargets()
+	}, beforeReport)
+}
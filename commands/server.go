@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"github.com/codegangsta/cli"
 	"github.com/mkboudreau/asrt/config"
 	"github.com/mkboudreau/asrt/execution"
+	"github.com/mkboudreau/asrt/storage"
 )
 
 const (
@@ -32,13 +34,23 @@ func cmdServer(ctx *cli.Context) {
 		log.Fatalln("Exiting....")
 	}
 
-	executor := execution.NewExecutor(c.AggregateOutput, c.FailuresOnly, c.StateChangeOnly, c.ResultFormatter(), c.Writer(), c.Workers)
+	// the handler caches everything written for each report, which it serves on /data
+	asrt := NewAsrtHandler(c, nil)
+	executor := execution.NewExecutor(c.AggregateOutput, c.FailuresOnly, c.StateChangeOnly, c.ResultFormatter(), c.WriterWithWriters(asrt), c.Workers)
+	executor.SetLimits(c.HostConcurrency, c.MaxRPS)
+	executor.SetStatePolicy(statePolicy(c))
+	history := useHistory(ctx, "server", c, executor)
 
-	asrt := NewAsrtHandler(c, executor)
-	asrt.refreshServerCache()
-	go asrt.loopServerCacheRefresh()
+	asrt.executor = executor
+	// every target is checked once before listening, so that /data has a report to serve from the start
+	scheduler := execution.NewScheduler(executor, c.CurrentTargets())
+	scheduler.CheckDue()
+	go scheduleTargets(c, executor, scheduler, nil)
 
 	http.Handle("/data", asrt)
+	if history != nil {
+		http.Handle("/history", NewHistoryHandler(history))
+	}
 	http.HandleFunc("/", serveStaticWebFiles)
 
 	fmt.Println("Listening on port:", ctx.String("port"))
@@ -57,6 +69,45 @@ func NewAsrtHandler(cfg *config.Configuration, executor *execution.Executor) *As
 	return &AsrtHandler{configuration: cfg, executor: executor, mutex: &sync.RWMutex{}}
 }
 
+// HistoryHandler serves stored results as json. The url and label parameters select a target and since,
+// a time.Duration, how far back to go; it defaults to 24h.
+type HistoryHandler struct {
+	store storage.Store
+}
+
+func NewHistoryHandler(store storage.Store) *HistoryHandler {
+	return &HistoryHandler{store: store}
+}
+
+func (h *HistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	since := 24 * time.Hour
+	if s := r.URL.Query().Get("since"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since %q: %v", s, err), http.StatusBadRequest)
+			return
+		}
+		since = d
+	}
+
+	records, err := h.store.Query(storage.Query{
+		Url:   r.URL.Query().Get("url"),
+		Label: r.URL.Query().Get("label"),
+		Since: time.Now().Add(-since),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if records == nil {
+		records = []*storage.Record{}
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
 func serveStaticWebFiles(w http.ResponseWriter, r *http.Request) {
 	fs := http.FileServer(http.Dir("www/dist"))
 	fs.ServeHTTP(w, r)
@@ -79,30 +130,6 @@ func (asrt *AsrtHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	asrt.mutex.RUnlock()
 }
 
-func (asrt *AsrtHandler) loopServerCacheRefresh() {
-	done := make(chan struct{})
-	fn := func() {
-		close(done)
-	}
-
-	OsSignalShutdown(fn, 5)
-
-	ticker := time.NewTicker(asrt.configuration.Rate)
-
-	for {
-		select {
-		case <-ticker.C:
-			asrt.refreshServerCache()
-		case <-done:
-			return
-		}
-	}
-}
-
-func (asrt *AsrtHandler) refreshServerCache() {
-	asrt.executor.Execute(asrt.configuration.Targets)
-}
-
 func (asrt *AsrtHandler) Write(p []byte) (n int, err error) {
 	if asrt.buffer == nil {
 		asrt.buffer = new(bytes.Buffer)
@@ -6,13 +6,14 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/mkboudreau/asrt/execution"
 )
 
 func OsSignalShutdown(doBeforeShutdown func(), shutdownDelay int) {
 	sigc := make(chan os.Signal, 2)
 
 	signal.Notify(sigc,
-		syscall.SIGHUP,
 		syscall.SIGINT,
 		syscall.SIGTERM,
 		syscall.SIGQUIT)
@@ -21,6 +22,7 @@ func OsSignalShutdown(doBeforeShutdown func(), shutdownDelay int) {
 		str := <-s
 		log.Println("Received signal:", str.String())
 		fn()
+		execution.CloseIdleConnections()
 
 		log.Printf("Shutting down after %v seconds\n", delay)
 		time.Sleep(time.Duration(delay) * time.Second)
@@ -28,3 +30,16 @@ func OsSignalShutdown(doBeforeShutdown func(), shutdownDelay int) {
 		os.Exit(0)
 	}(sigc, doBeforeShutdown, shutdownDelay)
 }
+
+// OsSignalReload calls doReload every time the process receives SIGHUP
+func OsSignalReload(doReload func()) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+
+	go func(s <-chan os.Signal, fn func()) {
+		for range s {
+			log.Println("Received signal: hangup, reloading targets")
+			fn()
+		}
+	}(sigc, doReload)
+}
@@ -28,6 +28,8 @@ func cmdStatus(ctx *cli.Context) {
 	}
 
 	executor := execution.NewExecutor(c.AggregateOutput, c.FailuresOnly, false, c.ResultFormatter(), c.Writer(), c.Workers)
+	executor.SetLimits(c.HostConcurrency, c.MaxRPS)
+	useHistory(ctx, "status", c, executor)
 	exitStatus := retryUntil(duration, func() int {
 		return executor.Execute(c.Targets)
 	})
@@ -35,6 +37,8 @@ func cmdStatus(ctx *cli.Context) {
 	os.Exit(exitStatus)
 }
 
+// retryUntil runs fn again while it fails, until the duration is up. Targets that only warned are as good as
+// they get, so exit status 2 is final like 0.
 func retryUntil(duration time.Duration, fn func() int) int {
 	retry := 2 * time.Second
 
@@ -46,7 +50,7 @@ func retryUntil(duration time.Duration, fn func() int) int {
 loop:
 	for {
 		lastResult = fn()
-		if lastResult == 0 {
+		if lastResult != 1 {
 			break loop
 		}
 		select {
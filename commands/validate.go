@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/execution"
+)
+
+func cmdValidate(ctx *cli.Context) {
+	targets, problems := config.ValidateRegisteredTargets(ctx)
+	problems = append(problems, schemeProblems(targets)...)
+	config.SortProblems(problems)
+	os.Exit(writeProblems(os.Stdout, targets, problems))
+}
+
+// writeProblems exits with 1 when there are errors and 2 when there are only warnings, like the status command
+func writeProblems(w io.Writer, targets []config.LocatedTarget, problems []config.Problem) int {
+	exitStatus := 0
+	errors, warnings := 0, 0
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+		if p.Severity == config.SeverityError {
+			errors++
+			exitStatus = 1
+		} else {
+			warnings++
+			if exitStatus == 0 {
+				exitStatus = 2
+			}
+		}
+	}
+	fmt.Fprintf(w, "%d targets, %d errors, %d warnings\n", len(targets), errors, warnings)
+	return exitStatus
+}
+
+// schemeProblems reports the targets whose URL scheme has no checker
+func schemeProblems(targets []config.LocatedTarget) []config.Problem {
+	var problems []config.Problem
+	for _, lt := range targets {
+		if _, err := execution.CheckerForTarget(lt.Target); err != nil {
+			problems = append(problems, config.NewProblem(lt.Location, config.SeverityError, "%v", err))
+		}
+	}
+	return problems
+}
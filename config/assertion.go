@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type AssertionType string
+
+const (
+	AssertBodyContains    AssertionType = "body-contains"
+	AssertBodyNotContains               = "body-not-contains"
+	AssertBodyRegex                     = "body-regex"
+	AssertBodyJSONPath                  = "body-jsonpath"
+	AssertHeaderExists                  = "header-exists"
+	AssertHeaderEquals                  = "header-equals"
+	AssertHeaderRegex                   = "header-regex"
+)
+
+const (
+	bodyAssertionPrefix        string = "{B}"
+	bodyAssertionNotPrefix            = "!"
+	bodyAssertionRegexPrefix          = "~"
+	bodyAssertionJSONPathStart        = "$"
+	headerAssertionPrefix             = "{A}"
+	headerAssertionRegexPrefix        = "~"
+)
+
+// Assertion describes a check made against a response in addition to the expected status code.
+// Path is only used by assertions that need to locate a value, such as a JSONPath or a header name.
+type Assertion struct {
+	Type  AssertionType
+	Path  string
+	Value string
+	regex *regexp.Regexp
+}
+
+// NewAssertion creates a new config.Assertion, validating any regular expression or path it carries.
+func NewAssertion(assertionType AssertionType, path string, value string) (*Assertion, error) {
+	a := &Assertion{Type: assertionType, Path: path, Value: value}
+
+	switch assertionType {
+	case AssertHeaderExists, AssertHeaderEquals, AssertHeaderRegex:
+		if path == "" {
+			return nil, fmt.Errorf("header assertion is missing a header name")
+		}
+	}
+
+	switch assertionType {
+	case AssertBodyContains, AssertBodyNotContains, AssertHeaderExists, AssertHeaderEquals:
+	case AssertBodyRegex, AssertHeaderRegex:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", value, err)
+		}
+		a.regex = re
+	case AssertBodyJSONPath:
+		if _, err := ParseJSONPath(path); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown assertion type %q", assertionType)
+	}
+
+	return a, nil
+}
+
+// Regexp returns the compiled regular expression for regex assertions and nil for all others.
+func (a *Assertion) Regexp() *regexp.Regexp {
+	if a.regex == nil && (a.Type == AssertBodyRegex || a.Type == AssertHeaderRegex) {
+		a.regex, _ = regexp.Compile(a.Value)
+	}
+	return a.regex
+}
+
+func (a *Assertion) String() string {
+	switch a.Type {
+	case AssertBodyContains:
+		return fmt.Sprintf("body contains %q", a.Value)
+	case AssertBodyNotContains:
+		return fmt.Sprintf("body does not contain %q", a.Value)
+	case AssertBodyRegex:
+		return fmt.Sprintf("body matches %q", a.Value)
+	case AssertBodyJSONPath:
+		return fmt.Sprintf("body %v == %q", a.Path, a.Value)
+	case AssertHeaderExists:
+		return fmt.Sprintf("header %v exists", a.Path)
+	case AssertHeaderEquals:
+		return fmt.Sprintf("header %v == %q", a.Path, a.Value)
+	case AssertHeaderRegex:
+		return fmt.Sprintf("header %v matches %q", a.Path, a.Value)
+	}
+	return fmt.Sprintf("%v %v %q", a.Type, a.Path, a.Value)
+}
+
+func isBodyAssertion(part string) bool {
+	return strings.HasPrefix(part, bodyAssertionPrefix)
+}
+
+// extractBodyAssertion parses {B}text (contains), {B}!text (does not contain), {B}~regex (matches)
+// and {B}$.json.path=value (JSONPath equals).
+func extractBodyAssertion(assertionWithPrefix string) (*Assertion, error) {
+	assertion := extractQuotedString(strings.TrimPrefix(assertionWithPrefix, bodyAssertionPrefix))
+
+	switch {
+	case strings.HasPrefix(assertion, bodyAssertionNotPrefix):
+		return NewAssertion(AssertBodyNotContains, "", strings.TrimPrefix(assertion, bodyAssertionNotPrefix))
+	case strings.HasPrefix(assertion, bodyAssertionRegexPrefix):
+		return NewAssertion(AssertBodyRegex, "", strings.TrimPrefix(assertion, bodyAssertionRegexPrefix))
+	case strings.HasPrefix(assertion, bodyAssertionJSONPathStart):
+		parts := strings.SplitN(assertion, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("json path assertion %q must be of the form $.path=value", assertion)
+		}
+		return NewAssertion(AssertBodyJSONPath, strings.TrimSpace(parts[0]), strings.TrimSpace(extractQuotedString(parts[1])))
+	default:
+		return NewAssertion(AssertBodyContains, "", assertion)
+	}
+}
+
+func isHeaderAssertion(part string) bool {
+	return strings.HasPrefix(part, headerAssertionPrefix)
+}
+
+// extractHeaderAssertion parses {A}Name (exists), {A}Name: value (equals) and {A}Name:~regex (matches).
+// The syntax mirrors the {H} request header prefix.
+func extractHeaderAssertion(assertionWithPrefix string) (*Assertion, error) {
+	assertion := extractQuotedString(strings.TrimPrefix(assertionWithPrefix, headerAssertionPrefix))
+
+	parts := strings.SplitN(assertion, ":", 2)
+	name := strings.TrimSpace(parts[0])
+	if len(parts) == 1 {
+		return NewAssertion(AssertHeaderExists, name, "")
+	}
+
+	value := strings.TrimSpace(parts[1])
+	if strings.HasPrefix(value, headerAssertionRegexPrefix) {
+		return NewAssertion(AssertHeaderRegex, name, strings.TrimPrefix(value, headerAssertionRegexPrefix))
+	}
+	return NewAssertion(AssertHeaderEquals, name, value)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	bodyPrefix     string = "{D}"
+	bodyFilePrefix        = "@"
+)
+
+func isBody(part string) bool {
+	return strings.HasPrefix(part, bodyPrefix)
+}
+
+// extractBody parses {D}inline data and {D}@file references, returning the inline body or the file name.
+func extractBody(bodyWithPrefix string) (body string, bodyFile string) {
+	data := extractQuotedString(strings.TrimPrefix(bodyWithPrefix, bodyPrefix))
+	if strings.HasPrefix(data, bodyFilePrefix) {
+		return "", strings.TrimPrefix(data, bodyFilePrefix)
+	}
+	return data, ""
+}
+
+// ResolveBodyFile makes a relative @file body relative to dir, the directory of the file the target was
+// read from, rather than to the working directory.
+func (t *Target) ResolveBodyFile(dir string) {
+	if t.BodyFile != "" && !filepath.IsAbs(t.BodyFile) {
+		t.BodyFile = filepath.Join(dir, t.BodyFile)
+	}
+}
+
+// LoadBody reads the target's @file body, if any, and infers the Content-Type of the body.
+// The file contents get the same environment expansion as target files unless expandEnv is false.
+func (t *Target) LoadBody(expandEnv bool) error {
+	if t.BodyFile != "" {
+		b, err := ioutil.ReadFile(t.BodyFile)
+		if err != nil {
+			return fmt.Errorf("could not read body file %v: %v", t.BodyFile, err)
+		}
+		t.Body = string(b)
+		if expandEnv {
+			t.Body = os.ExpandEnv(t.Body)
+		}
+	}
+	if t.Body != "" && t.BodyContentType == "" {
+		t.BodyContentType = inferContentType(t.BodyFile, t.Body)
+	}
+	return nil
+}
+
+// inferContentType prefers the body file's extension and falls back to sniffing the body itself
+func inferContentType(filename string, body string) string {
+	if ext := filepath.Ext(filename); ext != "" {
+		if contentType := mime.TypeByExtension(ext); contentType != "" {
+			return contentType
+		}
+	}
+
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)):
+		return "application/json"
+	case strings.HasPrefix(trimmed, "<"):
+		return "application/xml"
+	case isFormEncoded(trimmed):
+		return "application/x-www-form-urlencoded"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+func isFormEncoded(body string) bool {
+	if !strings.Contains(body, "=") || strings.ContainsAny(body, " \n\t") {
+		return false
+	}
+	_, err := url.ParseQuery(body)
+	return err == nil
+}
@@ -6,10 +6,12 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/codegangsta/cli"
 	"github.com/mkboudreau/asrt/output"
+	"github.com/mkboudreau/asrt/storage"
 )
 
 var (
@@ -20,7 +22,7 @@ var (
 	ErrInvalidFormat              = errors.New(fmt.Sprintf("Output format unknown. Valid formats are %v", ValidFormats))
 )
 
-var ValidFormats = []string{"csv", "csv-md", "csv-no-color", "tab", "tab-md", "tab-no-color", "json", "json-compact", "template={{...}}"}
+var ValidFormats = []string{"csv", "csv-md", "csv-no-color", "csv-wide", "tab", "tab-md", "tab-no-color", "tab-wide", "json", "json-compact", "template={{...}}"}
 var ValidMethods = []string{"GET", "PUT", "POST", "DELETE", "HEAD", "PATCH"}
 
 var DefaultHttpStatuses = map[string]int{
@@ -47,35 +49,111 @@ type Configuration struct {
 	context         *cli.Context
 	CommandName     string
 	Rate            time.Duration
+	Jitter          time.Duration
+	WatchInterval   time.Duration
 	FormatString    string
 	Output          OutputFormat
 	Pretty          bool
 	AggregateOutput bool
+	AggregateGroups bool
 	Quiet           bool
 	NoHeader        bool
 	Markdown        bool
+	Wide            bool
 	FailuresOnly    bool
 	StateChangeOnly bool
+	DownAfter       int
+	UpAfter         int
+	FlapChanges     int
+	FlapWindow      time.Duration
 	Workers         int
+	History         string
+	HistoryKeep     time.Duration
+	HostConcurrency int
+	MaxRPS          float64
+	WarnAfter       time.Duration
+	FailAfter       time.Duration
+	StrictTLS       bool
+	CertWarnDays    int
+	TLSCAFile       string
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSServerName   string
+	Proxy           string
+	FreshConnection bool
+	Retry           RetryPolicy
+	SLO             float64
+	Redirects       RedirectPolicy
+	Selection       TargetSelection
 	Targets         []*Target
+	targetsMutex    sync.RWMutex
 }
 
 func GetConfiguration(c *cli.Context) (*Configuration, error) {
 	config := &Configuration{context: c, CommandName: c.Command.Name, Targets: make([]*Target, 0)}
 
-	config.AggregateOutput = c.Bool("aggregate")
+	if err := applyRegisteredSettings(c); err != nil {
+		return nil, err
+	}
+
+	config.AggregateGroups = c.Bool("aggregate-groups")
+	config.AggregateOutput = c.Bool("aggregate") || config.AggregateGroups
 	config.NoHeader = c.Bool("no-header")
 	config.Quiet = c.Bool("quiet")
 	config.Workers = c.Int("workers")
+	config.History = c.String("history")
+	config.HistoryKeep = GetTimeDurationConfig(c, "history-retention")
+	config.HostConcurrency = c.Int("host-concurrency")
+	config.MaxRPS = c.Float64("max-rps")
 	config.FailuresOnly = c.Bool("failures-only")
 	config.StateChangeOnly = c.Bool("state-change-only")
+	config.DownAfter = c.Int("down-after")
+	config.UpAfter = c.Int("up-after")
+	config.FlapChanges = c.Int("flap-changes")
+	config.FlapWindow = GetTimeDurationConfig(c, "flap-window")
 
 	config.FormatString = c.String("format")
 	config.Output = GetOutputFormatOrDefault(config.FormatString, FormatTAB)
 	config.Pretty = GetPrettyOptionOrDefault(config.FormatString, true)
 	config.Markdown = GetMarkdownOptionOrDefault(config.FormatString, false)
+	config.Wide = GetWideOptionOrDefault(config.FormatString, false)
 
 	config.Rate = GetTimeDurationConfig(c, "rate")
+	config.Jitter = GetTimeDurationConfig(c, "jitter")
+	config.WatchInterval = GetTimeDurationConfig(c, "watch-interval")
+	config.WarnAfter = GetTimeDurationConfig(c, "warn-after")
+	config.FailAfter = GetTimeDurationConfig(c, "fail-after")
+	config.StrictTLS = c.Bool("strict-tls")
+	config.CertWarnDays = c.Int("cert-warn-days")
+	config.TLSCAFile = c.String("ca-file")
+	config.TLSCertFile = c.String("cert-file")
+	config.TLSKeyFile = c.String("key-file")
+	config.TLSServerName = c.String("server-name")
+	config.Proxy = c.String("proxy")
+	config.FreshConnection = c.Bool("fresh-connection")
+
+	config.Retry.Attempts = c.Int("attempts")
+	config.Retry.Delay = GetTimeDurationConfig(c, "retry-delay")
+	config.Retry.Jitter = GetTimeDurationConfig(c, "retry-jitter")
+	backoff, err := ParseBackoff(c.String("backoff"))
+	if err != nil {
+		return nil, err
+	}
+	config.Retry.Backoff = backoff
+
+	slo, err := ParseSLO(c.String("slo"))
+	if err != nil {
+		return nil, err
+	}
+	config.SLO = slo
+
+	redirects, err := ParseRedirectPolicy(c.String("redirects"))
+	if err != nil {
+		return nil, err
+	}
+	config.Redirects = redirects
+
+	config.Selection = NewTargetSelection(c.StringSlice("tag"), c.StringSlice("exclude-tag"), c.StringSlice("group"))
 
 	newTargets, err := getRegisteredConfigureredTargets(c)
 	if err != nil {
@@ -83,18 +161,101 @@ func GetConfiguration(c *cli.Context) (*Configuration, error) {
 	} else if len(newTargets) == 0 {
 		return nil, ErrInvalidTargets
 	}
+	newTargets = config.Selection.Select(newTargets)
+	if len(newTargets) == 0 {
+		return nil, ErrNoSelectedTargets
+	}
 
 	config.Targets = newTargets
+	config.applyTargetDefaults()
 
 	return config, nil
 }
 
+// applyTargetDefaults fills in target settings that were not set per target with the global settings.
+func (config *Configuration) applyTargetDefaults() {
+	config.applyDefaultsTo(config.Targets)
+}
+
+func (config *Configuration) applyDefaultsTo(targets []*Target) {
+	for _, t := range targets {
+		if t.Interval == 0 {
+			t.Interval = config.Rate
+		}
+		if t.Jitter == 0 {
+			t.Jitter = config.Jitter
+		}
+		if t.WarnAfter == 0 {
+			t.WarnAfter = config.WarnAfter
+		}
+		if t.FailAfter == 0 {
+			t.FailAfter = config.FailAfter
+		}
+		if !t.hasOption("strict-tls") {
+			t.StrictTLS = config.StrictTLS
+		}
+		if t.CertWarnDays == 0 {
+			t.CertWarnDays = config.CertWarnDays
+		}
+		if t.TLSCAFile == "" {
+			t.TLSCAFile = config.TLSCAFile
+		}
+		if t.TLSCertFile == "" && t.TLSKeyFile == "" {
+			t.TLSCertFile = config.TLSCertFile
+			t.TLSKeyFile = config.TLSKeyFile
+		}
+		if t.TLSServerName == "" {
+			t.TLSServerName = config.TLSServerName
+		}
+		if t.Proxy == "" {
+			t.Proxy = config.Proxy
+		}
+		if !t.hasOption("fresh-connection") {
+			t.FreshConnection = config.FreshConnection
+		}
+		if t.Retry.Attempts == 0 {
+			t.Retry.Attempts = config.Retry.Attempts
+		}
+		if t.Retry.Backoff == "" {
+			t.Retry.Backoff = config.Retry.Backoff
+		}
+		if t.Retry.Delay == 0 {
+			t.Retry.Delay = config.Retry.Delay
+		}
+		if t.Retry.Jitter == 0 {
+			t.Retry.Jitter = config.Retry.Jitter
+		}
+		if t.SLO == 0 {
+			t.SLO = config.SLO
+		}
+		if t.Redirects == RedirectDefault {
+			t.Redirects = config.Redirects
+		}
+	}
+}
+
+// WatchedFiles are the files the targets were read from.
+func (config *Configuration) WatchedFiles() []string {
+	return GetWatchedFiles(config.context)
+}
+
+// OpenHistory opens the history store, or returns nil when no history was asked for.
+func (config *Configuration) OpenHistory() (storage.Store, error) {
+	if config.History == "" {
+		return nil, nil
+	}
+	return storage.Open(config.History, config.HistoryKeep)
+}
+
 func (config *Configuration) ResultFormatter() output.ResultFormatter {
 	modifiers := &output.ResultFormatModifiers{
 		Pretty:    config.Pretty,
 		Aggregate: config.AggregateOutput,
+		Groups:    config.AggregateGroups,
 		NoHeader:  config.NoHeader,
 		Markdown:  config.Markdown,
+		Wide:      config.Wide,
+		Report:    config.CommandName == "report",
 	}
 
 	switch {
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrNoSelectedTargets error = errors.New("No targets match the given --tag, --exclude-tag and --group selection.")
+
+// TargetSelection picks targets by tag and group. A target is selected when it has any of Tags (or Tags is empty),
+// belongs to any of Groups (or Groups is empty) and has none of ExcludeTags.
+type TargetSelection struct {
+	Tags        []string
+	ExcludeTags []string
+	Groups      []string
+}
+
+// NewTargetSelection splits comma separated values, so that --tag a,b is the same as --tag a --tag b.
+func NewTargetSelection(tags []string, excludeTags []string, groups []string) TargetSelection {
+	return TargetSelection{
+		Tags:        splitList(tags),
+		ExcludeTags: splitList(excludeTags),
+		Groups:      splitList(groups),
+	}
+}
+
+// Matches is true when the target is selected.
+func (s TargetSelection) Matches(t *Target) bool {
+	if len(s.Groups) > 0 && !containsFold(s.Groups, t.Group) {
+		return false
+	}
+	if len(s.Tags) > 0 && !t.hasAnyTag(s.Tags) {
+		return false
+	}
+	return !t.hasAnyTag(s.ExcludeTags)
+}
+
+// Select returns the selected targets, in their original order.
+func (s TargetSelection) Select(targets []*Target) []*Target {
+	var selected []*Target
+	for _, t := range targets {
+		if s.Matches(t) {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
+
+// HasTag compares tags without regard to case. Tags are plain names, such as payments, or name=value pairs,
+// such as tier=critical.
+func (t *Target) HasTag(tag string) bool {
+	return containsFold(t.Tags, tag)
+}
+
+func (t *Target) hasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		if t.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags adds the comma separated tags the target does not have yet.
+func (t *Target) AddTags(tags ...string) {
+	for _, tag := range splitList(tags) {
+		if !t.HasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+}
+
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+	}
+	return list
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
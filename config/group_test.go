@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetParsingGroupAndTags(t *testing.T) {
+	target, err := ParseTarget("www.yahoo.com|{O}group=payments|{O}tag=tier=critical|{O}tag=eu,PCI")
+	assert.Nil(t, err)
+	assert.Equal(t, "payments", target.Group)
+	assert.Equal(t, []string{"tier=critical", "eu", "PCI"}, target.Tags)
+	assert.True(t, target.HasTag("pci"))
+	assert.False(t, target.HasTag("tier"))
+
+	_, err = ParseTarget("www.yahoo.com|{O}tag")
+	assert.NotNil(t, err)
+}
+
+func TestTargetSelection(t *testing.T) {
+	payments := &Target{URL: "payments", Group: "payments", Tags: []string{"tier=critical"}}
+	search := &Target{URL: "search", Group: "search", Tags: []string{"tier=low", "flaky"}}
+	untagged := &Target{URL: "untagged"}
+	targets := []*Target{payments, search, untagged}
+
+	testCases := []struct {
+		selection TargetSelection
+		expect    []*Target
+	}{
+		{NewTargetSelection(nil, nil, nil), targets},
+		{NewTargetSelection([]string{"tier=critical"}, nil, nil), []*Target{payments}},
+		{NewTargetSelection([]string{"tier=critical,flaky"}, nil, nil), []*Target{payments, search}},
+		{NewTargetSelection(nil, []string{"flaky"}, nil), []*Target{payments, untagged}},
+		{NewTargetSelection(nil, nil, []string{"Payments"}), []*Target{payments}},
+		{NewTargetSelection([]string{"flaky"}, nil, []string{"payments"}), nil},
+		{NewTargetSelection(nil, []string{"tier=critical"}, []string{"payments", "search"}), []*Target{search}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expect, tc.selection.Select(targets), "%+v", tc.selection)
+	}
+}
+
+func TestTargetDefinitionGroupAndTags(t *testing.T) {
+	target, err := (&TargetDefinition{URL: "www.yahoo.com", Group: "payments", Tags: []string{"tier=critical", "eu"}}).Target()
+	assert.Nil(t, err)
+	assert.Equal(t, "payments", target.Group)
+	assert.Equal(t, []string{"tier=critical", "eu"}, target.Tags)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a parsed subset of JSONPath: a root ($) followed by .name, ['name'] and [index] steps.
+type JSONPath []jsonPathStep
+
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// ParseJSONPath parses paths such as $.status, $.data.items[0].name or $['key with spaces'].
+func ParseJSONPath(path string) (JSONPath, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path %q must start with $", path)
+	}
+
+	var steps JSONPath
+	rest := path[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("json path %q has an empty key", path)
+			}
+			steps = append(steps, jsonPathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("json path %q has an unterminated [", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if quoted := extractQuotedString(inner); quoted != inner {
+				steps = append(steps, jsonPathStep{key: quoted})
+				continue
+			}
+			i, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("json path %q has an invalid index %q", path, inner)
+			}
+			steps = append(steps, jsonPathStep{index: i, isIndex: true})
+		default:
+			return nil, fmt.Errorf("json path %q is invalid near %q", path, rest)
+		}
+	}
+
+	return steps, nil
+}
+
+// Find walks a document decoded by encoding/json and returns the value at the path, if any.
+func (p JSONPath) Find(document interface{}) (interface{}, bool) {
+	current := document
+	for _, step := range p {
+		if step.isIndex {
+			list, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			i := step.index
+			if i < 0 {
+				i = len(list) + i
+			}
+			if i < 0 || i >= len(list) {
+				return nil, false
+			}
+			current = list[i]
+		} else {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[step.key]; !ok {
+				return nil, false
+			}
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const optionPrefix string = "{O}"
+
+// targetOptionSetter applies the value of a {O}name=value option to a target.
+type targetOptionSetter func(t *Target, value string) error
+
+var targetOptions = map[string]targetOptionSetter{
+	"interval": func(t *Target, value string) error {
+		d, err := parseOptionDuration(value)
+		t.Interval = d
+		return err
+	},
+	"jitter": func(t *Target, value string) error {
+		d, err := parseOptionDuration(value)
+		t.Jitter = d
+		return err
+	},
+	"warn-after": func(t *Target, value string) error {
+		d, err := parseOptionDuration(value)
+		t.WarnAfter = d
+		return err
+	},
+	"fail-after": func(t *Target, value string) error {
+		d, err := parseOptionDuration(value)
+		t.FailAfter = d
+		return err
+	},
+	"strict-tls": func(t *Target, value string) error {
+		b, err := parseOptionBool(value)
+		t.StrictTLS = b
+		return err
+	},
+	"cert-warn-days": func(t *Target, value string) error {
+		i, err := parseOptionInt(value)
+		t.CertWarnDays = i
+		return err
+	},
+	"ca-file": func(t *Target, value string) error {
+		t.TLSCAFile = value
+		return nil
+	},
+	"cert-file": func(t *Target, value string) error {
+		t.TLSCertFile = value
+		return nil
+	},
+	"key-file": func(t *Target, value string) error {
+		t.TLSKeyFile = value
+		return nil
+	},
+	"server-name": func(t *Target, value string) error {
+		t.TLSServerName = value
+		return nil
+	},
+	"proxy": func(t *Target, value string) error {
+		if _, err := url.Parse(value); err != nil {
+			return err
+		}
+		t.Proxy = value
+		return nil
+	},
+	"fresh-connection": func(t *Target, value string) error {
+		b, err := parseOptionBool(value)
+		t.FreshConnection = b
+		return err
+	},
+	"attempts": func(t *Target, value string) error {
+		i, err := parseOptionInt(value)
+		t.Retry.Attempts = i
+		return err
+	},
+	"backoff": func(t *Target, value string) error {
+		b, err := ParseBackoff(value)
+		t.Retry.Backoff = b
+		return err
+	},
+	"retry-delay": func(t *Target, value string) error {
+		d, err := parseOptionDuration(value)
+		t.Retry.Delay = d
+		return err
+	},
+	"retry-jitter": func(t *Target, value string) error {
+		d, err := parseOptionDuration(value)
+		t.Retry.Jitter = d
+		return err
+	},
+	"slo": func(t *Target, value string) error {
+		slo, err := ParseSLO(value)
+		t.SLO = slo
+		return err
+	},
+	"redirects": func(t *Target, value string) error {
+		p, err := ParseRedirectPolicy(value)
+		t.Redirects = p
+		return err
+	},
+	"final-url": func(t *Target, value string) error {
+		t.FinalURL = value
+		return nil
+	},
+	"send": func(t *Target, value string) error {
+		send, err := unescapeOption(value)
+		t.Send = send
+		return err
+	},
+	"expect": func(t *Target, value string) error {
+		expect, err := unescapeOption(value)
+		t.Expect = expect
+		return err
+	},
+	"group": func(t *Target, value string) error {
+		t.Group = value
+		return nil
+	},
+	"tag": func(t *Target, value string) error {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("tag must not be empty")
+		}
+		t.AddTags(value)
+		return nil
+	},
+}
+
+// ValidTargetOptions lists the names accepted by the {O}name=value target syntax.
+func ValidTargetOptions() []string {
+	var names []string
+	for name := range targetOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetOption applies a single named option to the target, as if it had been given as {O}name=value.
+func (t *Target) SetOption(name string, value string) error {
+	setter, ok := targetOptions[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown target option %q. Valid options are %v", name, ValidTargetOptions())
+	}
+	if err := setter(t, value); err != nil {
+		return fmt.Errorf("invalid value %q for target option %v: %v", value, name, err)
+	}
+	if t.options == nil {
+		t.options = make(map[string]bool)
+	}
+	t.options[strings.ToLower(name)] = true
+	return nil
+}
+
+// hasOption reports whether the option was set on the target, even when to its zero value.
+func (t *Target) hasOption(name string) bool {
+	return t.options[name]
+}
+
+func isOption(part string) bool {
+	return strings.HasPrefix(part, optionPrefix)
+}
+
+func extractOption(optionWithPrefix string) (string, string) {
+	option := extractQuotedString(strings.TrimPrefix(optionWithPrefix, optionPrefix))
+	optionParts := strings.SplitN(option, "=", 2)
+	if len(optionParts) == 1 {
+		return strings.TrimSpace(option), ""
+	}
+	return strings.TrimSpace(optionParts[0]), strings.TrimSpace(extractQuotedString(strings.TrimSpace(optionParts[1])))
+}
+
+func parseOptionDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration must not be negative")
+	}
+	return d, nil
+}
+
+// parseOptionBool treats a bare {O}name, with no value, as true
+func parseOptionBool(value string) (bool, error) {
+	if value == "" {
+		return true, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// unescapeOption turns escape sequences such as \r\n or \x00 into the bytes they stand for, so that
+// binary and line based protocols can be written on a single line
+func unescapeOption(value string) (string, error) {
+	var buffer bytes.Buffer
+	for len(value) > 0 {
+		r, multibyte, tail, err := strconv.UnquoteChar(value, 0)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape sequence in %q", value)
+		}
+		if multibyte {
+			buffer.WriteRune(r)
+		} else {
+			buffer.WriteByte(byte(r))
+		}
+		value = tail
+	}
+	return buffer.String(), nil
+}
+
+func parseOptionInt(value string) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("value must not be negative")
+	}
+	return i, nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxRedirects is how many redirects a target follows unless told otherwise.
+const DefaultMaxRedirects int = 10
+
+// RedirectPolicy is the maximum number of redirects a target follows. The zero value follows up to
+// DefaultMaxRedirects; RedirectNone returns the first response, redirect or not.
+type RedirectPolicy int
+
+const (
+	RedirectDefault RedirectPolicy = 0
+	RedirectNone                   = -1
+)
+
+// ParseRedirectPolicy accepts follow, none or a maximum number of hops. A maximum of 0 is the same as none.
+func ParseRedirectPolicy(value string) (RedirectPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "follow":
+		return RedirectPolicy(DefaultMaxRedirects), nil
+	case "none", "no-follow":
+		return RedirectNone, nil
+	}
+
+	hops, err := strconv.Atoi(value)
+	if err != nil || hops < 0 {
+		return RedirectDefault, fmt.Errorf("redirects must be follow, none or a number of hops")
+	}
+	if hops == 0 {
+		return RedirectNone, nil
+	}
+	return RedirectPolicy(hops), nil
+}
+
+// Follows reports whether any redirects are followed at all.
+func (p RedirectPolicy) Follows() bool {
+	return p != RedirectNone
+}
+
+// MaxHops is the number of redirects to follow before giving up.
+func (p RedirectPolicy) MaxHops() int {
+	switch {
+	case p == RedirectDefault:
+		return DefaultMaxRedirects
+	case p == RedirectNone:
+		return 0
+	}
+	return int(p)
+}
+
+func (p RedirectPolicy) String() string {
+	if p == RedirectNone {
+		return "none"
+	}
+	return strconv.Itoa(p.MaxHops())
+}
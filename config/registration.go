@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"strings"
 
 	"github.com/codegangsta/cli"
 )
@@ -31,6 +33,29 @@ func RegisterTargetConfigurer(tc TargetConfigurer) {
 	}
 }
 
+// SettingsConfigurer is implemented by target configurers whose source can also give global settings. The
+// settings are flag values by flag name, which are used for every flag not given on the command line.
+type SettingsConfigurer interface {
+	GetSettings(c *cli.Context) (map[string]string, error)
+}
+
+// WatchingConfigurer is implemented by target configurers that read their targets from files. Long running
+// commands reload the targets when any of the files change.
+type WatchingConfigurer interface {
+	GetWatchedFiles(c *cli.Context) []string
+}
+
+// GetWatchedFiles returns the files of every registered WatchingConfigurer.
+func GetWatchedFiles(c *cli.Context) []string {
+	var files []string
+	for _, tc := range GetTargetConfigurers() {
+		if wc, ok := tc.(WatchingConfigurer); ok {
+			files = append(files, wc.GetWatchedFiles(c)...)
+		}
+	}
+	return files
+}
+
 // WriterConfigurer ...
 type WriterConfigurer interface {
 	Configurer
@@ -78,3 +103,62 @@ func GetTargetConfigurers() []TargetConfigurer {
 	}
 	return configurers
 }
+
+// applyRegisteredSettings sets the flags given by settings configurers, unless they were given on the command line.
+// Settings for flags the command does not have are ignored, so that one file can be shared between commands.
+func applyRegisteredSettings(c *cli.Context) error {
+	for _, tc := range GetTargetConfigurers() {
+		sc, ok := tc.(SettingsConfigurer)
+		if !ok {
+			continue
+		}
+		settings, err := sc.GetSettings(c)
+		if err != nil {
+			return err
+		}
+		for name, value := range settings {
+			if err := applySetting(c, name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func applySetting(c *cli.Context, name string, value string) error {
+	names := flagNames(c, name)
+	if len(names) == 0 {
+		log.Printf("Ignoring setting %v: the %v command has no such flag\n", name, c.Command.Name)
+		return nil
+	}
+	for _, n := range names {
+		if c.IsSet(n) {
+			return nil
+		}
+	}
+	for _, n := range names {
+		if err := c.Set(n, value); err != nil {
+			return fmt.Errorf("invalid value %q for setting %v: %v", value, name, err)
+		}
+	}
+	return nil
+}
+
+// flagNames returns every name of the command flag that has the given name, such as format and fmt.
+func flagNames(c *cli.Context, name string) []string {
+	for _, f := range c.Command.Flags {
+		var names []string
+		found := false
+		for _, n := range strings.Split(f.GetName(), ",") {
+			n = strings.TrimSpace(n)
+			names = append(names, n)
+			if n == name {
+				found = true
+			}
+		}
+		if found {
+			return names
+		}
+	}
+	return nil
+}
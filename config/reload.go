@@ -0,0 +1,72 @@
+package config
+
+import (
+	"reflect"
+)
+
+// CurrentTargets returns the targets, which ReloadTargets may have replaced since the configuration was read.
+func (config *Configuration) CurrentTargets() []*Target {
+	config.targetsMutex.RLock()
+	defer config.targetsMutex.RUnlock()
+	return config.Targets
+}
+
+// ReloadTargets reads the targets of every registered target configurer again and swaps them in all at once.
+// It returns the targets that were removed or changed, whose earlier results no longer apply. The targets are
+// left as they were when reading them fails.
+func (config *Configuration) ReloadTargets() ([]*Target, error) {
+	newTargets, err := getRegisteredConfigureredTargets(config.context)
+	if err != nil {
+		return nil, err
+	} else if len(newTargets) == 0 {
+		return nil, ErrInvalidTargets
+	}
+	newTargets = config.Selection.Select(newTargets)
+	if len(newTargets) == 0 {
+		return nil, ErrNoSelectedTargets
+	}
+	config.applyDefaultsTo(newTargets)
+
+	config.targetsMutex.Lock()
+	oldTargets := config.Targets
+	config.Targets = newTargets
+	config.targetsMutex.Unlock()
+
+	return staleTargets(oldTargets, newTargets), nil
+}
+
+// staleTargets are the old targets without an identical new target of the same url and label
+func staleTargets(oldTargets []*Target, newTargets []*Target) []*Target {
+	var stale []*Target
+	for _, old := range oldTargets {
+		kept := false
+		for _, t := range newTargets {
+			if t.URL == old.URL && t.Label == old.Label && t.Equal(old) {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			stale = append(stale, old)
+		}
+	}
+	return stale
+}
+
+// Equal reports whether both targets are defined the same way.
+func (t *Target) Equal(other *Target) bool {
+	if len(t.Assertions) != len(other.Assertions) {
+		return false
+	}
+	for i, a := range t.Assertions {
+		b := other.Assertions[i]
+		if a.Type != b.Type || a.Path != b.Path || a.Value != b.Value {
+			return false
+		}
+	}
+
+	// assertions hold compiled regular expressions, so they are compared by what they were created from above
+	left, right := *t, *other
+	left.Assertions, right.Assertions = nil, nil
+	return reflect.DeepEqual(left, right)
+}
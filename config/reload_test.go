@@ -0,0 +1,72 @@
+package config
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTargetConfigurer struct {
+	lines []string
+}
+
+func (tc *testTargetConfigurer) GetCommandFlags() []cli.Flag {
+	return nil
+}
+
+func (tc *testTargetConfigurer) GetTargets(c *cli.Context) ([]*Target, error) {
+	var targets []*Target
+	for _, line := range tc.lines {
+		t, err := ParseTarget(line)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func TestReloadTargets(t *testing.T) {
+	tc := &testTargetConfigurer{lines: []string{"www.yahoo.com", "www.google.com|{B}~up", "www.bing.com"}}
+	RegisterTargetConfigurer(tc)
+	defer delete(registeredTargetConfigurers, tc)
+
+	c := cli.NewContext(cli.NewApp(), flag.NewFlagSet("dashboard", flag.ContinueOnError), nil)
+	config := &Configuration{context: c, WarnAfter: 5}
+	targets, _ := tc.GetTargets(c)
+	config.Targets = targets
+	config.applyTargetDefaults()
+
+	tc.lines = []string{"www.yahoo.com", "www.google.com|{B}~down", "www.asrt.io"}
+	stale, err := config.ReloadTargets()
+	assert.Nil(t, err)
+	assert.Equal(t, []*Target{targets[1], targets[2]}, stale)
+
+	current := config.CurrentTargets()
+	assert.Len(t, current, 3)
+	assert.Equal(t, "http://www.asrt.io", current[2].URL)
+	assert.EqualValues(t, 5, current[2].WarnAfter)
+
+	tc.lines = []string{"www.yahoo.com|{O}attempts=many"}
+	_, err = config.ReloadTargets()
+	assert.NotNil(t, err)
+	assert.Equal(t, current, config.CurrentTargets())
+}
+
+func TestTargetEqual(t *testing.T) {
+	a, _ := ParseTarget("www.yahoo.com|GET|200|{B}~up|{O}warn-after=1s")
+	b, _ := ParseTarget("www.yahoo.com|GET|200|{B}~up|{O}warn-after=1s")
+	assert.True(t, a.Equal(b))
+
+	for _, line := range []string{
+		"www.yahoo.com|GET|200|{B}~down|{O}warn-after=1s",
+		"www.yahoo.com|GET|200|{B}~up|{O}warn-after=2s",
+		"www.yahoo.com|GET|204|{B}~up|{O}warn-after=1s",
+		"www.yahoo.com|GET|200|{B}~up|{O}warn-after=1s|{H}Accept: text/plain",
+	} {
+		other, _ := ParseTarget(line)
+		assert.False(t, a.Equal(other), line)
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+type Backoff string
+
+const (
+	BackoffFixed       Backoff = "fixed"
+	BackoffExponential         = "exponential"
+)
+
+var ValidBackoffs = []string{string(BackoffFixed), BackoffExponential}
+
+// MaxRetryDelay caps how long exponential backoff waits between two attempts.
+const MaxRetryDelay time.Duration = time.Minute
+
+// RetryPolicy decides how often a failing target is tried again before it is reported as failed.
+type RetryPolicy struct {
+	Attempts int
+	Backoff  Backoff
+	Delay    time.Duration
+	Jitter   time.Duration
+}
+
+// ParseBackoff accepts fixed or exponential. An empty value is fixed.
+func ParseBackoff(value string) (Backoff, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", string(BackoffFixed):
+		return BackoffFixed, nil
+	case BackoffExponential:
+		return BackoffExponential, nil
+	}
+	return "", fmt.Errorf("backoff must be one of %v", ValidBackoffs)
+}
+
+// MaxAttempts is at least 1, the first attempt.
+func (p RetryPolicy) MaxAttempts() int {
+	if p.Attempts < 1 {
+		return 1
+	}
+	return p.Attempts
+}
+
+// DelayAfter is how long to wait after the given failed attempt, counting from 1. Exponential backoff
+// doubles the delay after every attempt, up to MaxRetryDelay, and jitter adds a random part of up to Jitter.
+func (p RetryPolicy) DelayAfter(attempt int) time.Duration {
+	delay := p.Delay
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attempt && delay < MaxRetryDelay; i++ {
+			delay *= 2
+		}
+		if delay > MaxRetryDelay {
+			delay = MaxRetryDelay
+		}
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+	return delay
+}
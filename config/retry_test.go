@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	fixed := RetryPolicy{Attempts: 3, Backoff: BackoffFixed, Delay: time.Second}
+	assert.Equal(t, time.Second, fixed.DelayAfter(1))
+	assert.Equal(t, time.Second, fixed.DelayAfter(4))
+
+	exponential := RetryPolicy{Attempts: 5, Backoff: BackoffExponential, Delay: time.Second}
+	assert.Equal(t, time.Second, exponential.DelayAfter(1))
+	assert.Equal(t, 2*time.Second, exponential.DelayAfter(2))
+	assert.Equal(t, 8*time.Second, exponential.DelayAfter(4))
+	assert.Equal(t, MaxRetryDelay, exponential.DelayAfter(20))
+
+	jittered := RetryPolicy{Attempts: 2, Delay: time.Second, Jitter: 100 * time.Millisecond}
+	for i := 0; i < 20; i++ {
+		d := jittered.DelayAfter(1)
+		assert.True(t, d >= time.Second && d < 1100*time.Millisecond, "%v out of range", d)
+	}
+}
+
+func TestRetryPolicyMaxAttempts(t *testing.T) {
+	assert.Equal(t, 1, RetryPolicy{}.MaxAttempts())
+	assert.Equal(t, 1, RetryPolicy{Attempts: 1}.MaxAttempts())
+	assert.Equal(t, 4, RetryPolicy{Attempts: 4}.MaxAttempts())
+}
+
+func TestTargetParsingRetryOptions(t *testing.T) {
+	target, err := ParseTarget("www.yahoo.com|{O}attempts=3|{O}backoff=exponential|{O}retry-delay=250ms|{O}retry-jitter=50ms")
+	assert.Nil(t, err)
+	assert.Equal(t, RetryPolicy{Attempts: 3, Backoff: BackoffExponential, Delay: 250 * time.Millisecond, Jitter: 50 * time.Millisecond}, target.Retry)
+
+	for _, tc := range []string{"www.yahoo.com|{O}attempts=many", "www.yahoo.com|{O}backoff=linear", "www.yahoo.com|{O}retry-delay=-1s"} {
+		_, err := ParseTarget(tc)
+		assert.NotNil(t, err, tc)
+	}
+}
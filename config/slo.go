@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseSLO parses an availability objective such as 99.9 or 99.9%. An empty value is no objective.
+func ParseSLO(value string) (float64, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "%")
+	if value == "" {
+		return 0, nil
+	}
+
+	slo, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if slo <= 0 || slo >= 100 {
+		return 0, fmt.Errorf("slo must be a percentage between 0 and 100, such as 99.9")
+	}
+	return slo, nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusExpectation is a set of acceptable HTTP status codes. It is written as a comma separated
+// list of codes (200), classes (2xx) and ranges (200-299), such as 200,204 or 2xx,304.
+type StatusExpectation struct {
+	text   string
+	ranges []statusRange
+}
+
+type statusRange struct {
+	low, high int
+}
+
+// NewStatusExpectation creates an expectation for a single status code.
+func NewStatusExpectation(statusCode int) *StatusExpectation {
+	return &StatusExpectation{
+		text:   strconv.Itoa(statusCode),
+		ranges: []statusRange{{statusCode, statusCode}},
+	}
+}
+
+// ParseStatusExpectation parses the comma separated list of codes, classes and ranges.
+func ParseStatusExpectation(expectation string) (*StatusExpectation, error) {
+	e := &StatusExpectation{text: expectation}
+
+	for _, part := range strings.Split(expectation, ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		var r statusRange
+
+		switch {
+		case len(part) == 3 && strings.HasSuffix(part, "xx"):
+			class, err := strconv.Atoi(part[:1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid status class %q", part)
+			}
+			r = statusRange{class * 100, class*100 + 99}
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			low, lowErr := strconv.Atoi(bounds[0])
+			high, highErr := strconv.Atoi(bounds[1])
+			if lowErr != nil || highErr != nil || low > high {
+				return nil, fmt.Errorf("invalid status range %q", part)
+			}
+			r = statusRange{low, high}
+		default:
+			code, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid status code %q", part)
+			}
+			r = statusRange{code, code}
+		}
+
+		if r.low <= 0 || r.high >= 600 {
+			return nil, fmt.Errorf("status %q is out of range", part)
+		}
+		e.ranges = append(e.ranges, r)
+	}
+
+	return e, nil
+}
+
+// Matches reports whether the status code is acceptable.
+func (e *StatusExpectation) Matches(statusCode int) bool {
+	for _, r := range e.ranges {
+		if statusCode >= r.low && statusCode <= r.high {
+			return true
+		}
+	}
+	return false
+}
+
+// First is the lowest status code that would match, used where a single code is needed.
+func (e *StatusExpectation) First() int {
+	first := 0
+	for _, r := range e.ranges {
+		if first == 0 || r.low < first {
+			first = r.low
+		}
+	}
+	return first
+}
+
+// String returns the expectation as it was written.
+func (e *StatusExpectation) String() string {
+	return e.text
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testCasesForStatusExpectation = []struct {
+	text     string
+	matches  []int
+	misses   []int
+	first    int
+	hasError bool
+}{
+	{"200", []int{200}, []int{201, 199}, 200, false},
+	{"200,204", []int{200, 204}, []int{201, 203, 205}, 200, false},
+	{"2xx", []int{200, 250, 299}, []int{199, 300}, 200, false},
+	{"2XX", []int{200, 299}, []int{300}, 200, false},
+	{"200-299", []int{200, 250, 299}, []int{199, 300}, 200, false},
+	{"301, 302, 4xx", []int{301, 302, 404}, []int{300, 303, 500}, 301, false},
+	{"304,2xx", []int{304, 204}, []int{301}, 200, false},
+	{"6xx", nil, nil, 0, true},
+	{"299-200", nil, nil, 0, true},
+	{"abc", nil, nil, 0, true},
+	{"200,", nil, nil, 0, true},
+	{"0-100", nil, nil, 0, true},
+}
+
+func TestParseStatusExpectation(t *testing.T) {
+	for _, tc := range testCasesForStatusExpectation {
+		e, err := ParseStatusExpectation(tc.text)
+		if tc.hasError {
+			assert.NotNil(t, err, tc.text)
+			continue
+		}
+		assert.Nil(t, err, tc.text)
+		assert.Equal(t, tc.text, e.String())
+		assert.Equal(t, tc.first, e.First(), tc.text)
+		for _, code := range tc.matches {
+			assert.True(t, e.Matches(code), "%v should match %v", tc.text, code)
+		}
+		for _, code := range tc.misses {
+			assert.False(t, e.Matches(code), "%v should not match %v", tc.text, code)
+		}
+	}
+}
+
+func TestTargetParsingStatusExpectation(t *testing.T) {
+	target, err := ParseTarget("www.yahoo.com|GET|200,204|Label")
+	assert.Nil(t, err)
+	assert.Equal(t, "Label", target.Label)
+	assert.Equal(t, 200, target.ExpectedStatus)
+	assert.Equal(t, "200,204", target.StatusExpectation().String())
+	assert.True(t, target.StatusExpectation().Matches(204))
+
+	target, err = ParseTarget("www.yahoo.com|DELETE|2xx")
+	assert.Nil(t, err)
+	assert.Equal(t, "2xx", target.StatusExpectation().String())
+	assert.True(t, target.StatusExpectation().Matches(202))
+
+	target, err = ParseTarget("www.yahoo.com|200-299|Label")
+	assert.Nil(t, err)
+	assert.Equal(t, "200-299", target.StatusExpectation().String())
+
+	target, err = ParseTarget("www.yahoo.com|POST")
+	assert.Nil(t, err)
+	assert.Nil(t, target.AcceptStatuses)
+	assert.Equal(t, "201", target.StatusExpectation().String())
+
+	target, err = ParseTarget("www.yahoo.com|my-label")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-label", target.Label)
+	assert.Equal(t, "200", target.StatusExpectation().String())
+}
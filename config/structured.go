@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StructuredFile is the YAML or JSON form of a target file. Besides its targets, it can give the global
+// settings that are otherwise command line flags. Flags given on the command line take precedence.
+type StructuredFile struct {
+	Workers  int                `yaml:"workers"`
+	Timeout  string             `yaml:"timeout"`
+	Format   string             `yaml:"format"`
+	Rate     string             `yaml:"rate"`
+	Writers  map[string]string  `yaml:"writers"`
+	Settings map[string]string  `yaml:"settings"`
+	Targets  []TargetDefinition `yaml:"targets"`
+}
+
+// TargetDefinition is one target of a StructuredFile. Assertions use the line format without their {B} or {A}
+// prefix, and every {O}name=value option can be given as a key of its own, such as warn-after: 2s.
+type TargetDefinition struct {
+	URL         string                 `yaml:"url"`
+	Method      string                 `yaml:"method"`
+	Status      string                 `yaml:"status"`
+	Label       string                 `yaml:"label"`
+	Group       string                 `yaml:"group"`
+	Tags        []string               `yaml:"tags"`
+	Timeout     string                 `yaml:"timeout"`
+	Headers     map[string]string      `yaml:"headers"`
+	Body        string                 `yaml:"body"`
+	BodyFile    string                 `yaml:"body_file"`
+	ContentType string                 `yaml:"content_type"`
+	Assertions  AssertionDefinitions   `yaml:"assertions"`
+	Extra       map[string]interface{} `yaml:"extra"`
+	Options     map[string]interface{} `yaml:",inline"`
+	Line        int                    `yaml:"-"` // line of the file the target starts on, when known
+}
+
+// AssertionDefinitions are the body and header assertions of a TargetDefinition.
+type AssertionDefinitions struct {
+	Body    []string `yaml:"body"`
+	Headers []string `yaml:"headers"`
+}
+
+// SettingsFlags returns the global settings of the file by flag name.
+func (f *StructuredFile) SettingsFlags() map[string]string {
+	flags := make(map[string]string)
+	for name, value := range f.Settings {
+		flags[name] = value
+	}
+	for name, value := range f.Writers {
+		flags[name] = value
+	}
+	if f.Workers != 0 {
+		flags["workers"] = strconv.Itoa(f.Workers)
+	}
+	if f.Timeout != "" {
+		flags["timeout"] = f.Timeout
+	}
+	if f.Format != "" {
+		flags["format"] = f.Format
+	}
+	if f.Rate != "" {
+		flags["rate"] = f.Rate
+	}
+	return flags
+}
+
+// Target creates the config.Target the definition describes, with the same defaults as ParseTarget.
+func (d *TargetDefinition) Target() (*Target, error) {
+	if d.URL == "" {
+		return nil, fmt.Errorf("target needs a url")
+	}
+
+	method := MethodGet
+	if d.Method != "" {
+		if !isHttpMethod(strings.ToUpper(d.Method)) {
+			return nil, ErrInvalidMethod
+		}
+		method = extractMethod(strings.ToUpper(d.Method))
+	}
+
+	statusCode := extractStatusCode("", method)
+	var acceptStatuses *StatusExpectation
+	if d.Status != "" {
+		if isStatusCode(d.Status) {
+			statusCode = extractStatusCode(d.Status, method)
+		} else {
+			e, err := ParseStatusExpectation(d.Status)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse status %v of target %v: %v", d.Status, d.URL, err)
+			}
+			acceptStatuses = e
+			statusCode = e.First()
+		}
+	}
+
+	t, err := NewTarget(d.Label, d.URL, method, statusCode)
+	if err != nil {
+		return nil, fmt.Errorf("could not create target with url %v: %v", d.URL, err)
+	}
+	t.AcceptStatuses = acceptStatuses
+	t.Group = d.Group
+	t.AddTags(d.Tags...)
+
+	if d.Timeout != "" {
+		timeout, err := time.ParseDuration(d.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse timeout %v of target %v: %v", d.Timeout, d.URL, err)
+		}
+		t.Timeout = timeout
+	}
+
+	if len(d.Headers) > 0 {
+		t.Headers = make(map[string]string)
+		for key, value := range d.Headers {
+			t.Headers[key] = value
+		}
+	}
+
+	t.Body = d.Body
+	t.BodyFile = d.BodyFile
+	t.BodyContentType = d.ContentType
+	if t.Body != "" && t.BodyContentType == "" {
+		t.BodyContentType = inferContentType("", t.Body)
+	}
+
+	for _, a := range d.Assertions.Body {
+		assertion, err := extractBodyAssertion(bodyAssertionPrefix + a)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse body assertion %v: %v", a, err)
+		}
+		t.Assertions = append(t.Assertions, assertion)
+	}
+	for _, a := range d.Assertions.Headers {
+		assertion, err := extractHeaderAssertion(headerAssertionPrefix + a)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse header assertion %v: %v", a, err)
+		}
+		t.Assertions = append(t.Assertions, assertion)
+	}
+
+	for key, data := range d.Extra {
+		t.AddExtra(key, data)
+	}
+
+	// options are applied in name order so that errors are reported the same way every time
+	var names []string
+	for name := range d.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := t.SetOption(name, fmt.Sprint(d.Options[name])); err != nil {
+			return nil, fmt.Errorf("target %v: %v", d.URL, err)
+		}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetDefinitionDefaults(t *testing.T) {
+	target, err := (&TargetDefinition{URL: "www.yahoo.com"}).Target()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://www.yahoo.com", target.URL)
+	assert.Equal(t, CommandMethod(MethodGet), target.Method)
+	assert.Equal(t, 200, target.ExpectedStatus)
+
+	target, err = (&TargetDefinition{URL: "www.yahoo.com", Method: "post"}).Target()
+	assert.Nil(t, err)
+	assert.Equal(t, CommandMethod(MethodPost), target.Method)
+	assert.Equal(t, 201, target.ExpectedStatus)
+}
+
+func TestTargetDefinitionFields(t *testing.T) {
+	d := &TargetDefinition{
+		URL:         "https://api.example.com/orders",
+		Method:      "PUT",
+		Status:      "2xx",
+		Label:       "orders",
+		Timeout:     "3s",
+		Headers:     map[string]string{"Authorization": "Bearer abc"},
+		Body:        `{"id": 1}`,
+		ContentType: "application/vnd.api+json",
+		Assertions: AssertionDefinitions{
+			Body:    []string{"$.status == ok", "!error"},
+			Headers: []string{"X-Request-Id", "Cache-Control:~max-age"},
+		},
+		Extra:   map[string]interface{}{"team": "payments"},
+		Options: map[string]interface{}{"warn-after": "500ms", "attempts": 3, "strict-tls": true},
+	}
+
+	target, err := d.Target()
+	assert.Nil(t, err)
+	assert.Equal(t, CommandMethod(MethodPut), target.Method)
+	assert.Equal(t, 200, target.ExpectedStatus)
+	assert.Equal(t, "2xx", target.StatusExpectation().String())
+	assert.True(t, target.StatusExpectation().Matches(204))
+	assert.Equal(t, "orders", target.Label)
+	assert.Equal(t, 3*time.Second, target.Timeout)
+	assert.Equal(t, "Bearer abc", target.Headers["Authorization"])
+	assert.Equal(t, `{"id": 1}`, target.Body)
+	assert.Equal(t, "application/vnd.api+json", target.BodyContentType)
+	assert.Len(t, target.Assertions, 4)
+	assert.Equal(t, AssertionType(AssertBodyNotContains), target.Assertions[1].Type)
+	assert.Equal(t, AssertionType(AssertHeaderExists), target.Assertions[2].Type)
+	assert.Equal(t, AssertionType(AssertHeaderRegex), target.Assertions[3].Type)
+	assert.Equal(t, "payments", target.Extra["team"])
+	assert.Equal(t, 500*time.Millisecond, target.WarnAfter)
+	assert.Equal(t, 3, target.Retry.Attempts)
+	assert.True(t, target.StrictTLS)
+}
+
+func TestTargetDefinitionErrors(t *testing.T) {
+	for _, d := range []*TargetDefinition{
+		{},
+		{URL: "www.yahoo.com", Method: "FETCH"},
+		{URL: "www.yahoo.com", Status: "2zz"},
+		{URL: "www.yahoo.com", Timeout: "soon"},
+		{URL: "www.yahoo.com", Options: map[string]interface{}{"unknown": "value"}},
+		{URL: "www.yahoo.com", Options: map[string]interface{}{"attempts": "many"}},
+	} {
+		_, err := d.Target()
+		assert.NotNil(t, err, "%+v should not be a valid target", d)
+	}
+}
+
+func TestStructuredFileSettingsFlags(t *testing.T) {
+	f := &StructuredFile{
+		Workers:  4,
+		Timeout:  "5s",
+		Format:   "json",
+		Rate:     "1m",
+		Writers:  map[string]string{"slack-url": "https://hooks.example.com"},
+		Settings: map[string]string{"attempts": "2"},
+	}
+	assert.Equal(t, map[string]string{
+		"workers":   "4",
+		"timeout":   "5s",
+		"format":    "json",
+		"rate":      "1m",
+		"slack-url": "https://hooks.example.com",
+		"attempts":  "2",
+	}, f.SettingsFlags())
+}
+
+func TestApplySettingKeepsCommandLineFlags(t *testing.T) {
+	flags := []cli.Flag{
+		cli.StringFlag{Name: "format, fmt", Value: "tab"},
+		cli.IntFlag{Name: "workers, w", Value: 10},
+	}
+	set := flag.NewFlagSet("status", flag.ContinueOnError)
+	for _, f := range flags {
+		f.Apply(set)
+	}
+	assert.Nil(t, set.Parse([]string{"-fmt", "csv"}))
+	c := cli.NewContext(cli.NewApp(), set, nil)
+	c.Command = cli.Command{Name: "status", Flags: flags}
+
+	assert.Nil(t, applySetting(c, "format", "json"))
+	assert.Nil(t, applySetting(c, "workers", "3"))
+	assert.Nil(t, applySetting(c, "port", "8080"))
+	assert.Equal(t, "csv", c.String("fmt"))
+	assert.Equal(t, 3, c.Int("workers"))
+	assert.Equal(t, 3, c.Int("w"))
+}
+
+func TestApplySettingInvalidValue(t *testing.T) {
+	flags := []cli.Flag{cli.IntFlag{Name: "workers, w", Value: 10}}
+	set := flag.NewFlagSet("status", flag.ContinueOnError)
+	for _, f := range flags {
+		f.Apply(set)
+	}
+	c := cli.NewContext(cli.NewApp(), set, nil)
+	c.Command = cli.Command{Name: "status", Flags: flags}
+
+	assert.NotNil(t, applySetting(c, "workers", "many"))
+}
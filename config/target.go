@@ -20,13 +20,40 @@ const (
 )
 
 type Target struct {
-	Label          string
-	Method         CommandMethod
-	Timeout        time.Duration
-	ExpectedStatus int
-	URL            string
-	Headers        map[string]string
-	Extra          map[string]interface{}
+	Label           string
+	Group           string
+	Tags            []string
+	Method          CommandMethod
+	Timeout         time.Duration
+	Interval        time.Duration
+	Jitter          time.Duration
+	WarnAfter       time.Duration
+	FailAfter       time.Duration
+	StrictTLS       bool
+	CertWarnDays    int
+	TLSCAFile       string
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSServerName   string
+	Proxy           string
+	FreshConnection bool
+	Retry           RetryPolicy
+	SLO             float64
+	ExpectedStatus  int
+	AcceptStatuses  *StatusExpectation
+	Redirects       RedirectPolicy
+	FinalURL        string
+	URL             string
+	Headers         map[string]string
+	Body            string
+	BodyFile        string
+	BodyContentType string
+	Send            string
+	Expect          string
+	Assertions      []*Assertion
+	Extra           map[string]interface{}
+	// options that were given, so that global defaults do not override an option that was set to its zero value
+	options map[string]bool
 }
 
 // NewTarget creates a new config.Target object with the required fields.
@@ -58,19 +85,43 @@ func (t *Target) AddExtra(key string, data interface{}) {
 	t.Extra[key] = data
 }
 
-// ParseTarget takes a string of format <url>|<method>|<status_code>|<label>|<header> and parses it into a config.Target object. URL is the only required field.
+// ParseTarget takes a string of format <url>|<method>|<status_code>|<label>|<header>|<body>|<assertion>|<option> and parses it into a config.Target object. URL is the only required field.
 func ParseTarget(targetString string) (*Target, error) {
 	url, theRest := extractURL(targetString)
 	var method CommandMethod
 	var label string
 	var statusCode int
+	var acceptStatuses *StatusExpectation
 	var headers = make(map[string]string)
+	var assertions []*Assertion
+	var options [][2]string
+	var body, bodyFile string
 
 	for _, part := range theRest {
 		if string(method) == "" && isHttpMethod(part) {
 			method = extractMethod(part)
 		} else if statusCode == 0 && isStatusCode(part) {
 			statusCode = extractStatusCode(part, method)
+		} else if statusCode == 0 && isStatusExpectation(part) {
+			acceptStatuses, _ = ParseStatusExpectation(part)
+			statusCode = acceptStatuses.First()
+		} else if isBodyAssertion(part) {
+			assertion, err := extractBodyAssertion(part)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse body assertion %v: %v", part, err)
+			}
+			assertions = append(assertions, assertion)
+		} else if isHeaderAssertion(part) {
+			assertion, err := extractHeaderAssertion(part)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse header assertion %v: %v", part, err)
+			}
+			assertions = append(assertions, assertion)
+		} else if isBody(part) {
+			body, bodyFile = extractBody(part)
+		} else if isOption(part) {
+			name, value := extractOption(part)
+			options = append(options, [2]string{name, value})
 		} else if !isHeader(part) && label == "" {
 			label = extractLabel(part)
 		} else if isHeader(part) {
@@ -92,9 +143,21 @@ func ParseTarget(targetString string) (*Target, error) {
 		return nil, fmt.Errorf("could not create target with url %v: %v", url, err)
 	}
 	t.Method = method
+	t.AcceptStatuses = acceptStatuses
 	if len(headers) > 0 {
 		t.Headers = headers
 	}
+	t.Body = body
+	t.BodyFile = bodyFile
+	if body != "" {
+		t.BodyContentType = inferContentType("", body)
+	}
+	t.Assertions = assertions
+	for _, option := range options {
+		if err := t.SetOption(option[0], option[1]); err != nil {
+			return nil, err
+		}
+	}
 
 	return t, nil
 }
@@ -191,6 +254,23 @@ func isStatusCode(statusCodeString string) bool {
 	return false
 }
 
+// isStatusExpectation accepts lists, classes and ranges of status codes, such as 200,204, 2xx or 200-299
+func isStatusExpectation(statusString string) bool {
+	if !strings.ContainsAny(statusString, ",-xX") {
+		return false
+	}
+	_, err := ParseStatusExpectation(statusString)
+	return err == nil
+}
+
+// StatusExpectation returns the acceptable status codes, which is only ExpectedStatus unless a list or range was given.
+func (t *Target) StatusExpectation() *StatusExpectation {
+	if t.AcceptStatuses != nil {
+		return t.AcceptStatuses
+	}
+	return NewStatusExpectation(t.ExpectedStatus)
+}
+
 func extractLabel(labelString string) string {
 	return extractQuotedString(labelString)
 }
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -63,3 +66,249 @@ func assertMapContains(t *testing.T, targetMap, checkMap map[string]string) {
 		assert.Equal(t, v, targetMap[k])
 	}
 }
+
+var testCasesForBodyAssertionParsing = []struct {
+	target string
+	expect []*Assertion
+}{
+	{"www.yahoo.com|{B}UP", []*Assertion{{Type: AssertBodyContains, Value: "UP"}}},
+	{"www.yahoo.com|{B}\"status: UP\"", []*Assertion{{Type: AssertBodyContains, Value: "status: UP"}}},
+	{"www.yahoo.com|{B}!DOWN", []*Assertion{{Type: AssertBodyNotContains, Value: "DOWN"}}},
+	{"www.yahoo.com|{B}~^ok$", []*Assertion{{Type: AssertBodyRegex, Value: "^ok$"}}},
+	{"www.yahoo.com|{B}$.status=UP", []*Assertion{{Type: AssertBodyJSONPath, Path: "$.status", Value: "UP"}}},
+	{"www.yahoo.com|GET|200|Label|{H}Test: Value|{B}UP|{B}$.data[0].ok=true", []*Assertion{
+		{Type: AssertBodyContains, Value: "UP"},
+		{Type: AssertBodyJSONPath, Path: "$.data[0].ok", Value: "true"},
+	}},
+}
+
+func TestTargetParsingBodyAssertions(t *testing.T) {
+	for _, tc := range testCasesForBodyAssertionParsing {
+		target, err := ParseTarget(tc.target)
+		assert.Nil(t, err)
+		assert.NotNil(t, target)
+		assert.Len(t, target.Assertions, len(tc.expect))
+		for i, a := range tc.expect {
+			assert.Equal(t, a.Type, target.Assertions[i].Type)
+			assert.Equal(t, a.Path, target.Assertions[i].Path)
+			assert.Equal(t, a.Value, target.Assertions[i].Value)
+		}
+	}
+}
+
+func TestTargetParsingInvalidBodyAssertions(t *testing.T) {
+	for _, tc := range []string{"www.yahoo.com|{B}~[", "www.yahoo.com|{B}$.status", "www.yahoo.com|{B}$..status=UP"} {
+		target, err := ParseTarget(tc)
+		assert.NotNil(t, err, tc)
+		assert.Nil(t, target, tc)
+	}
+}
+
+var testCasesForHeaderAssertionParsing = []struct {
+	target string
+	expect *Assertion
+}{
+	{"www.yahoo.com|{A}X-Backend-Health", &Assertion{Type: AssertHeaderExists, Path: "X-Backend-Health"}},
+	{"www.yahoo.com|{A}X-Backend-Health: OK", &Assertion{Type: AssertHeaderEquals, Path: "X-Backend-Health", Value: "OK"}},
+	{"www.yahoo.com|{A}\"Cache-Control: no-cache, no-store\"", &Assertion{Type: AssertHeaderEquals, Path: "Cache-Control", Value: "no-cache, no-store"}},
+	{"www.yahoo.com|{A}Cache-Control:~max-age=\\d+", &Assertion{Type: AssertHeaderRegex, Path: "Cache-Control", Value: "max-age=\\d+"}},
+	{"www.yahoo.com|GET|200|Label|{H}Test: Value|{A}X-Test", &Assertion{Type: AssertHeaderExists, Path: "X-Test"}},
+}
+
+func TestTargetParsingHeaderAssertions(t *testing.T) {
+	for _, tc := range testCasesForHeaderAssertionParsing {
+		target, err := ParseTarget(tc.target)
+		assert.Nil(t, err)
+		assert.NotNil(t, target)
+		assert.Len(t, target.Assertions, 1)
+		assert.Equal(t, tc.expect.Type, target.Assertions[0].Type)
+		assert.Equal(t, tc.expect.Path, target.Assertions[0].Path)
+		assert.Equal(t, tc.expect.Value, target.Assertions[0].Value)
+		assert.NotContains(t, target.Label, "{A}")
+	}
+}
+
+func TestTargetParsingLatencyOptions(t *testing.T) {
+	target, err := ParseTarget("www.yahoo.com|GET|200|Label|{O}warn-after=500ms|{O}fail-after=2s")
+	assert.Nil(t, err)
+	assert.Equal(t, "Label", target.Label)
+	assert.Equal(t, 500*time.Millisecond, target.WarnAfter)
+	assert.Equal(t, 2*time.Second, target.FailAfter)
+
+	for _, tc := range []string{"www.yahoo.com|{O}warn-after=abc", "www.yahoo.com|{O}fail-after=-1s", "www.yahoo.com|{O}unknown=1"} {
+		target, err := ParseTarget(tc)
+		assert.NotNil(t, err, tc)
+		assert.Nil(t, target, tc)
+	}
+}
+
+func TestTargetParsingTLSOptions(t *testing.T) {
+	target, err := ParseTarget("https://www.yahoo.com|{O}strict-tls|{O}cert-warn-days=30")
+	assert.Nil(t, err)
+	assert.True(t, target.StrictTLS)
+	assert.Equal(t, 30, target.CertWarnDays)
+
+	target, err = ParseTarget("https://www.yahoo.com|{O}strict-tls=false")
+	assert.Nil(t, err)
+	assert.False(t, target.StrictTLS)
+
+	config := &Configuration{StrictTLS: true}
+	other, _ := ParseTarget("https://www.yahoo.com")
+	config.applyDefaultsTo([]*Target{target, other})
+	assert.False(t, target.StrictTLS)
+	assert.True(t, other.StrictTLS)
+
+	_, err = ParseTarget("https://www.yahoo.com|{O}cert-warn-days=soon")
+	assert.NotNil(t, err)
+}
+
+func TestTargetParsingTLSFileOptions(t *testing.T) {
+	target, err := ParseTarget("https://internal|{O}ca-file=/etc/ca.pem|{O}cert-file=client.pem|{O}key-file=client-key.pem|{O}server-name=api.internal")
+	assert.Nil(t, err)
+	assert.Equal(t, "/etc/ca.pem", target.TLSCAFile)
+	assert.Equal(t, "client.pem", target.TLSCertFile)
+	assert.Equal(t, "client-key.pem", target.TLSKeyFile)
+	assert.Equal(t, "api.internal", target.TLSServerName)
+}
+
+func TestTargetParsingConnectionOptions(t *testing.T) {
+	target, err := ParseTarget("https://www.yahoo.com|{O}proxy=http://proxy.internal:3128|{O}fresh-connection")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://proxy.internal:3128", target.Proxy)
+	assert.True(t, target.FreshConnection)
+
+	target, err = ParseTarget("https://www.yahoo.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "", target.Proxy)
+	assert.False(t, target.FreshConnection)
+
+	shared, err := ParseTarget("https://www.yahoo.com|{O}fresh-connection=false")
+	assert.Nil(t, err)
+	config := &Configuration{FreshConnection: true}
+	config.applyDefaultsTo([]*Target{target, shared})
+	assert.True(t, target.FreshConnection)
+	assert.False(t, shared.FreshConnection)
+}
+
+func TestTargetParsingScheduleOptions(t *testing.T) {
+	target, err := ParseTarget("www.yahoo.com|{O}interval=10s|{O}jitter=2s")
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Second, target.Interval)
+	assert.Equal(t, 2*time.Second, target.Jitter)
+
+	config := &Configuration{Rate: 30 * time.Second, Jitter: time.Second}
+	other, _ := ParseTarget("www.yahoo.com")
+	config.applyDefaultsTo([]*Target{target, other})
+	assert.Equal(t, 10*time.Second, target.Interval)
+	assert.Equal(t, 30*time.Second, other.Interval)
+	assert.Equal(t, time.Second, other.Jitter)
+
+	for _, tc := range []string{"www.yahoo.com|{O}interval=often", "www.yahoo.com|{O}jitter=-1s"} {
+		_, err := ParseTarget(tc)
+		assert.NotNil(t, err, tc)
+	}
+}
+
+func TestTargetParsingSendAndExpectOptions(t *testing.T) {
+	target, err := ParseTarget(`tcp://redis:6379|{O}send=PING\r\n|{O}expect=+PONG`)
+	assert.Nil(t, err)
+	assert.Equal(t, "PING\r\n", target.Send)
+	assert.Equal(t, "+PONG", target.Expect)
+
+	target, err = ParseTarget(`tcp://db:5432|{O}send=\x00\x00\x00\x08`)
+	assert.Nil(t, err)
+	assert.Equal(t, "\x00\x00\x00\x08", target.Send)
+
+	_, err = ParseTarget(`tcp://redis:6379|{O}send=PING\q`)
+	assert.NotNil(t, err)
+}
+
+func TestTargetParsingSLOOption(t *testing.T) {
+	target, err := ParseTarget("www.yahoo.com|{O}slo=99.9")
+	assert.Nil(t, err)
+	assert.Equal(t, 99.9, target.SLO)
+
+	target, err = ParseTarget("www.yahoo.com|{O}slo=99.5%")
+	assert.Nil(t, err)
+	assert.Equal(t, 99.5, target.SLO)
+
+	for _, tc := range []string{"www.yahoo.com|{O}slo=100", "www.yahoo.com|{O}slo=0", "www.yahoo.com|{O}slo=high"} {
+		_, err := ParseTarget(tc)
+		assert.NotNil(t, err, tc)
+	}
+}
+
+func TestTargetParsingRedirectOptions(t *testing.T) {
+	target, err := ParseTarget("http://www.yahoo.com|GET|301|{O}redirects=none")
+	assert.Nil(t, err)
+	assert.False(t, target.Redirects.Follows())
+	assert.Equal(t, 0, target.Redirects.MaxHops())
+
+	target, err = ParseTarget("http://www.yahoo.com|{O}redirects=3|{O}final-url=https://www.yahoo.com/")
+	assert.Nil(t, err)
+	assert.True(t, target.Redirects.Follows())
+	assert.Equal(t, 3, target.Redirects.MaxHops())
+	assert.Equal(t, "https://www.yahoo.com/", target.FinalURL)
+
+	target, err = ParseTarget("http://www.yahoo.com")
+	assert.Nil(t, err)
+	assert.Equal(t, RedirectDefault, target.Redirects)
+	assert.Equal(t, DefaultMaxRedirects, target.Redirects.MaxHops())
+
+	target, err = ParseTarget("http://www.yahoo.com|{O}redirects=0")
+	assert.Nil(t, err)
+	assert.False(t, target.Redirects.Follows())
+
+	for _, tc := range []string{"www.yahoo.com|{O}redirects=sometimes", "www.yahoo.com|{O}redirects=-2"} {
+		_, err := ParseTarget(tc)
+		assert.NotNil(t, err, tc)
+	}
+}
+
+var testCasesForBodyParsing = []struct {
+	target      string
+	body        string
+	bodyFile    string
+	contentType string
+}{
+	{"www.yahoo.com|POST|{D}'{\"name\": \"asrt\"}'", `{"name": "asrt"}`, "", "application/json"},
+	{"www.yahoo.com|POST|{D}[1,2,3]", "[1,2,3]", "", "application/json"},
+	{"www.yahoo.com|PUT|{D}<ping/>", "<ping/>", "", "application/xml"},
+	{"www.yahoo.com|POST|{D}name=asrt&debug=true", "name=asrt&debug=true", "", "application/x-www-form-urlencoded"},
+	{"www.yahoo.com|POST|{D}\"hello world\"", "hello world", "", "text/plain; charset=utf-8"},
+	{"www.yahoo.com|POST|{D}@payload.json", "", "payload.json", ""},
+}
+
+func TestTargetParsingBody(t *testing.T) {
+	for _, tc := range testCasesForBodyParsing {
+		target, err := ParseTarget(tc.target)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.body, target.Body, tc.target)
+		assert.Equal(t, tc.bodyFile, target.BodyFile, tc.target)
+		assert.Equal(t, tc.contentType, target.BodyContentType, tc.target)
+		assert.Equal(t, "", target.Label, tc.target)
+	}
+}
+
+func TestTargetLoadBodyFromFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "asrt-body-*.json")
+	assert.Nil(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString(`{"token": "${ASRT_TEST_BODY_TOKEN}"}`)
+	f.Close()
+	os.Setenv("ASRT_TEST_BODY_TOKEN", "secret")
+	defer os.Unsetenv("ASRT_TEST_BODY_TOKEN")
+
+	target, err := ParseTarget("www.yahoo.com|POST|{D}@" + f.Name())
+	assert.Nil(t, err)
+	assert.Nil(t, target.LoadBody(true))
+	assert.Equal(t, `{"token": "secret"}`, target.Body)
+	assert.Equal(t, "application/json", target.BodyContentType)
+
+	target, _ = ParseTarget("www.yahoo.com|POST|{D}@" + f.Name())
+	assert.Nil(t, target.LoadBody(false))
+	assert.Equal(t, `{"token": "${ASRT_TEST_BODY_TOKEN}"}`, target.Body)
+
+	target, _ = ParseTarget("www.yahoo.com|POST|{D}@/does/not/exist.json")
+	assert.NotNil(t, target.LoadBody(true))
+}
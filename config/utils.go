@@ -32,6 +32,15 @@ func GetMarkdownOptionOrDefault(val string, defaultBoolean bool) bool {
 	}
 }
 
+func GetWideOptionOrDefault(val string, defaultBoolean bool) bool {
+	v := strings.ToUpper(val)
+	if strings.Contains(v, "-WIDE") {
+		return true
+	} else {
+		return defaultBoolean
+	}
+}
+
 func GetPrettyOptionOrDefault(val string, defaultBoolean bool) bool {
 	v := strings.ToUpper(val)
 	if GetMarkdownOptionOrDefault(val, false) {
@@ -135,3 +135,26 @@ func TestGetPrettyOptionOrDefault(t *testing.T) {
 		}
 	}
 }
+
+var wideOptionTestCases = []struct {
+	Text              string
+	Default, Expected bool
+}{
+	{"csv", false, false},
+	{"csv", true, true},
+	{"tab-md", false, false},
+	{"csv-wide", false, true},
+	{"TAB-WIDE", false, true},
+	{"tab-wide-md", false, true},
+	{"tab-no-color-wide", false, true},
+	{"tab-wid", false, false},
+}
+
+func TestGetWideOptionOrDefault(t *testing.T) {
+	for _, test := range wideOptionTestCases {
+		actual := GetWideOptionOrDefault(test.Text, test.Default)
+		if actual != test.Expected {
+			t.Errorf("Expected %v, but got %v with text %v", test.Expected, actual, test.Text)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning          = "warning"
+)
+
+// Location is where a target was defined, such as a file and line. Line is 0 when it is not known.
+type Location struct {
+	Source string
+	Line   int
+}
+
+func (l Location) String() string {
+	if l.Line > 0 {
+		return fmt.Sprintf("%v:%d", l.Source, l.Line)
+	}
+	return l.Source
+}
+
+// Problem is an error or warning found while validating targets.
+type Problem struct {
+	Location
+	Severity Severity
+	Message  string
+}
+
+func NewProblem(location Location, severity Severity, format string, a ...interface{}) Problem {
+	return Problem{Location: location, Severity: severity, Message: fmt.Sprintf(format, a...)}
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%v: %v: %v", p.Location, p.Severity, p.Message)
+}
+
+// LocatedTarget is a target along with where it was defined.
+type LocatedTarget struct {
+	Location
+	Target *Target
+}
+
+// ValidatingConfigurer is implemented by target configurers that can tell where each of their targets was
+// defined. It parses the targets without making any requests and reports every problem instead of the first.
+type ValidatingConfigurer interface {
+	ValidateTargets(c *cli.Context) ([]LocatedTarget, []Problem)
+}
+
+// ValidateRegisteredTargets validates the targets of every registered target configurer. Configurers that are
+// not ValidatingConfigurers only report the error of GetTargets, if any.
+func ValidateRegisteredTargets(c *cli.Context) ([]LocatedTarget, []Problem) {
+	var targets []LocatedTarget
+	var problems []Problem
+
+	for _, tc := range GetTargetConfigurers() {
+		if vc, ok := tc.(ValidatingConfigurer); ok {
+			newTargets, newProblems := vc.ValidateTargets(c)
+			targets = append(targets, newTargets...)
+			problems = append(problems, newProblems...)
+			continue
+		}
+		newTargets, err := tc.GetTargets(c)
+		location := Location{Source: fmt.Sprint(tc)}
+		if err != nil {
+			problems = append(problems, NewProblem(location, SeverityError, "%v", err))
+		}
+		for _, t := range newTargets {
+			targets = append(targets, LocatedTarget{Location: location, Target: t})
+		}
+	}
+
+	problems = append(problems, duplicateTargets(targets)...)
+	if len(targets) == 0 && len(problems) == 0 {
+		problems = append(problems, NewProblem(Location{Source: c.App.Name}, SeverityError, "%v", ErrInvalidTargets))
+	}
+
+	SortProblems(problems)
+	return targets, problems
+}
+
+// SortProblems orders problems by source and line.
+func SortProblems(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Source != problems[j].Source {
+			return problems[i].Source < problems[j].Source
+		}
+		return problems[i].Line < problems[j].Line
+	})
+}
+
+// duplicateTargets warns about targets that send the same request as an earlier one
+func duplicateTargets(targets []LocatedTarget) []Problem {
+	var problems []Problem
+	seen := make(map[string]Location)
+	for _, lt := range targets {
+		key := fmt.Sprintf("%v %v %v", lt.Target.Method, lt.Target.URL, lt.Target.Body)
+		if first, ok := seen[key]; ok {
+			problems = append(problems, NewProblem(lt.Location, SeverityWarning, "duplicate target %v %v, first defined at %v", lt.Target.Method, lt.Target.URL, first))
+			continue
+		}
+		seen[key] = lt.Location
+	}
+	return problems
+}
+
+var (
+	unknownPrefixPattern = regexp.MustCompile(`^\{[A-Za-z]\}`)
+	statusLikePattern    = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// unsupportedMethods are standard HTTP methods that targets cannot use
+var unsupportedMethods = []string{"OPTIONS", "CONNECT", "TRACE"}
+
+// LintTarget parses the target like ParseTarget, and also warns about the tokens that ParseTarget accepts
+// silently but that were probably not meant that way, such as a misspelled method GTE becoming the label.
+func LintTarget(targetString string) (*Target, []string, error) {
+	t, err := ParseTarget(targetString)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	var hasMethod, hasStatus bool
+	label := ""
+
+	_, parts := extractURL(targetString)
+	for _, part := range parts {
+		switch {
+		case !hasMethod && isHttpMethod(part):
+			hasMethod = true
+			continue
+		case !hasStatus && (isStatusCode(part) || isStatusExpectation(part)):
+			hasStatus = true
+			continue
+		case isBodyAssertion(part), isHeaderAssertion(part), isBody(part), isOption(part):
+			continue
+		case isHeader(part):
+			warnings = append(warnings, lintHeader(part)...)
+			continue
+		case strings.TrimSpace(part) == "":
+			warnings = append(warnings, "empty field")
+			continue
+		}
+
+		reason := lintReason(part, hasMethod, hasStatus)
+		if label != "" {
+			warnings = append(warnings, fmt.Sprintf("%q is ignored because the target already has the label %q%v", part, label, reason))
+			continue
+		}
+		label = extractLabel(part)
+		if reason != "" {
+			warnings = append(warnings, fmt.Sprintf("%q is used as the label%v", part, reason))
+		}
+	}
+
+	return t, warnings, nil
+}
+
+// lintReason explains why a token that ended up as the label looks like something else
+func lintReason(part string, hasMethod bool, hasStatus bool) string {
+	switch {
+	case unknownPrefixPattern.MatchString(part):
+		return fmt.Sprintf(", but %v is not a known prefix. Valid prefixes are {H}, {D}, {B}, {A} and {O}", part[:3])
+	case isHttpMethod(part) && hasMethod:
+		return ", but it looks like a second method"
+	case containsFold(unsupportedMethods, part):
+		return fmt.Sprintf(", but it looks like a method. Valid methods are %v", ValidMethods)
+	case strings.ToUpper(part) == part && looksLikeMethod(part):
+		return fmt.Sprintf(", but it looks like a misspelled method. Valid methods are %v", ValidMethods)
+	case isHttpMethod(strings.ToUpper(part)):
+		return ", but it looks like a method. Methods must be upper case"
+	case (isStatusCode(part) || isStatusExpectation(part)) && hasStatus:
+		return ", but it looks like a second status code"
+	case statusLikePattern.MatchString(part):
+		return ", but it looks like a status code. Status codes must be between 1 and 599"
+	}
+	return ""
+}
+
+func lintHeader(part string) []string {
+	header := extractQuotedString(strings.TrimPrefix(part, "{H}"))
+	if !strings.Contains(header, ":") {
+		return []string{fmt.Sprintf("header %q is not of the form {H}Name: value and is sent with an empty value", part)}
+	}
+	if name, _ := extractHeader(part); name == "" || strings.ContainsAny(name, " \t") {
+		return []string{fmt.Sprintf("header %q does not have a valid name", part)}
+	}
+	return nil
+}
+
+// looksLikeMethod is true for tokens within two edits of a valid method, such as GTE or PSOT
+func looksLikeMethod(part string) bool {
+	for _, method := range ValidMethods {
+		if editDistance(part, method) <= 2 && len(part) >= 3 {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
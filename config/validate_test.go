@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintTargetWarnings(t *testing.T) {
+	testCases := []struct {
+		target string
+		expect []string
+	}{
+		{"www.yahoo.com", nil},
+		{`www.yahoo.com|GET|200|"Main API"|{H}"Accept: text/plain"`, nil},
+		{"www.yahoo.com|API", nil},
+		{"www.yahoo.com|GTE", []string{`"GTE" is used as the label, but it looks like a misspelled method`}},
+		{"www.yahoo.com|get", []string{`"get" is used as the label, but it looks like a method. Methods must be upper case`}},
+		{"www.yahoo.com|OPTIONS", []string{`"OPTIONS" is used as the label, but it looks like a method`}},
+		{"www.yahoo.com|GET|POST", []string{`"POST" is used as the label, but it looks like a second method`}},
+		{"www.yahoo.com|2000", []string{`"2000" is used as the label, but it looks like a status code`}},
+		{"www.yahoo.com|200|404", []string{`"404" is used as the label, but it looks like a second status code`}},
+		{"www.yahoo.com|Main|Other", []string{`"Other" is ignored because the target already has the label "Main"`}},
+		{"www.yahoo.com|{X}value", []string{`"{X}value" is used as the label, but {X} is not a known prefix`}},
+		{"www.yahoo.com|Main|{h}Accept: text/plain", []string{`"{h}Accept: text/plain" is ignored because the target already has the label "Main", but {h} is not a known prefix`}},
+		{"www.yahoo.com|{H}Accept", []string{`header "{H}Accept" is not of the form {H}Name: value`}},
+		{"www.yahoo.com|{H}: text/plain", []string{`header "{H}: text/plain" does not have a valid name`}},
+		{"www.yahoo.com||GET", []string{"empty field"}},
+	}
+
+	for _, tc := range testCases {
+		_, warnings, err := LintTarget(tc.target)
+		assert.Nil(t, err, tc.target)
+		if assert.Len(t, warnings, len(tc.expect), "%v: %v", tc.target, warnings) {
+			for i, expect := range tc.expect {
+				assert.True(t, strings.HasPrefix(warnings[i], expect), "%v: %q should start with %q", tc.target, warnings[i], expect)
+			}
+		}
+	}
+}
+
+func TestLintTargetErrors(t *testing.T) {
+	_, _, err := LintTarget("www.yahoo.com|{O}unknown=1")
+	assert.NotNil(t, err)
+}
+
+func TestDuplicateTargets(t *testing.T) {
+	yahoo, _ := ParseTarget("www.yahoo.com")
+	yahooAgain, _ := ParseTarget("www.yahoo.com|GET|200|again")
+	yahooPost, _ := ParseTarget("www.yahoo.com|POST")
+
+	problems := duplicateTargets([]LocatedTarget{
+		{Location{"sites.list", 1}, yahoo},
+		{Location{"sites.list", 2}, yahooPost},
+		{Location{"argument 1", 0}, yahooAgain},
+	})
+	assert.Equal(t, []Problem{{
+		Location: Location{"argument 1", 0},
+		Severity: SeverityWarning,
+		Message:  "duplicate target GET http://www.yahoo.com, first defined at sites.list:1",
+	}}, problems)
+}
+
+func TestProblemString(t *testing.T) {
+	assert.Equal(t, "sites.list:3: error: bad", NewProblem(Location{"sites.list", 3}, SeverityError, "bad").String())
+	assert.Equal(t, "argument 1: warning: bad", NewProblem(Location{Source: "argument 1"}, SeverityWarning, "bad").String())
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("GET", "GET"))
+	assert.Equal(t, 2, editDistance("GTE", "GET"))
+	assert.Equal(t, 1, editDistance("POT", "POST"))
+	assert.Equal(t, 3, editDistance("API", "PUT"))
+}
@@ -0,0 +1,129 @@
+package execution
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mkboudreau/asrt/config"
+)
+
+// assertionFailure records the assertion that did not hold along with what was actually observed.
+type assertionFailure struct {
+	Assertion *config.Assertion
+	Observed  string
+}
+
+func (f *assertionFailure) String() string {
+	if f.Observed == "" {
+		return f.Assertion.String()
+	}
+	return fmt.Sprintf("%v (observed %v)", f.Assertion, f.Observed)
+}
+
+func hasBodyAssertions(assertions []*config.Assertion) bool {
+	for _, a := range assertions {
+		switch a.Type {
+		case config.AssertBodyContains, config.AssertBodyNotContains, config.AssertBodyRegex, config.AssertBodyJSONPath:
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateAssertions returns the first assertion that does not hold for the response, or nil when all pass.
+func evaluateAssertions(assertions []*config.Assertion, resp *response) *assertionFailure {
+	var document interface{}
+	var documentErr error
+	documentParsed := false
+
+	for _, a := range assertions {
+		switch a.Type {
+		case config.AssertBodyContains:
+			if !bytes.Contains(resp.Body, []byte(a.Value)) {
+				return &assertionFailure{Assertion: a}
+			}
+		case config.AssertBodyNotContains:
+			if bytes.Contains(resp.Body, []byte(a.Value)) {
+				return &assertionFailure{Assertion: a}
+			}
+		case config.AssertBodyRegex:
+			re := a.Regexp()
+			if re == nil || !re.Match(resp.Body) {
+				return &assertionFailure{Assertion: a}
+			}
+		case config.AssertBodyJSONPath:
+			if !documentParsed {
+				documentErr = json.Unmarshal(resp.Body, &document)
+				documentParsed = true
+			}
+			if documentErr != nil {
+				return &assertionFailure{Assertion: a, Observed: "invalid json"}
+			}
+			observed, ok := findJSONPathValue(a.Path, document)
+			if !ok {
+				return &assertionFailure{Assertion: a, Observed: "no value"}
+			}
+			if observed != a.Value {
+				return &assertionFailure{Assertion: a, Observed: fmt.Sprintf("%q", observed)}
+			}
+		case config.AssertHeaderExists, config.AssertHeaderEquals, config.AssertHeaderRegex:
+			if failure := evaluateHeaderAssertion(a, resp); failure != nil {
+				return failure
+			}
+		}
+	}
+
+	return nil
+}
+
+func evaluateHeaderAssertion(a *config.Assertion, resp *response) *assertionFailure {
+	values, ok := resp.Header[http.CanonicalHeaderKey(a.Path)]
+	if !ok || len(values) == 0 {
+		return &assertionFailure{Assertion: a, Observed: "missing"}
+	}
+	observed := strings.Join(values, ", ")
+
+	switch a.Type {
+	case config.AssertHeaderEquals:
+		if observed != a.Value {
+			return &assertionFailure{Assertion: a, Observed: fmt.Sprintf("%q", observed)}
+		}
+	case config.AssertHeaderRegex:
+		re := a.Regexp()
+		if re == nil || !re.MatchString(observed) {
+			return &assertionFailure{Assertion: a, Observed: fmt.Sprintf("%q", observed)}
+		}
+	}
+	return nil
+}
+
+func findJSONPathValue(path string, document interface{}) (string, bool) {
+	p, err := config.ParseJSONPath(path)
+	if err != nil {
+		return "", false
+	}
+	v, ok := p.Find(document)
+	if !ok {
+		return "", false
+	}
+	return jsonValueString(v), true
+}
+
+// jsonValueString renders a decoded json value the way it would be written in a target file.
+func jsonValueString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case nil:
+		return "null"
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,96 @@
+package execution
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var testCasesForBodyAssertions = []struct {
+	assertion string
+	success   bool
+}{
+	{"{B}DOWN", true},
+	{"{B}UP", false},
+	{"{B}!UP", true},
+	{"{B}!DOWN", false},
+	{"{B}~\"status\":\\s*\"DOWN\"", true},
+	{"{B}~^UP", false},
+	{"{B}$.status=DOWN", true},
+	{"{B}$.status=UP", false},
+	{"{B}$.checks[1].healthy=false", true},
+	{"{B}$.checks[0].latency=12", true},
+	{"{B}$.missing=UP", false},
+}
+
+func TestExecuteTargetBodyAssertions(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status": "DOWN", "checks": [{"name": "db", "latency": 12, "healthy": true}, {"name": "cache", "healthy": false}]}`))
+	}))
+	defer testServer.Close()
+
+	for _, tc := range testCasesForBodyAssertions {
+		target, err := config.ParseTarget(testServer.URL + "|" + tc.assertion)
+		assert.Nil(t, err, tc.assertion)
+
+		result := ExecuteTarget(target)
+		assert.Nil(t, result.Error, tc.assertion)
+		assert.Equal(t, 200, result.Actual, tc.assertion)
+		assert.Equal(t, tc.success, result.Success(), tc.assertion)
+		if tc.success {
+			assert.Empty(t, result.Assertion, tc.assertion)
+		} else {
+			assert.NotEmpty(t, result.Assertion, tc.assertion)
+		}
+	}
+}
+
+func TestExecuteTargetBodyAssertionOnInvalidJson(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html>DOWN</html>`))
+	}))
+	defer testServer.Close()
+
+	target, _ := config.ParseTarget(testServer.URL + "|{B}$.status=UP")
+	result := ExecuteTarget(target)
+
+	assert.False(t, result.Success())
+	assert.Contains(t, result.Assertion, "invalid json")
+}
+
+var testCasesForHeaderAssertions = []struct {
+	assertion string
+	success   bool
+	observed  string
+}{
+	{"{A}X-Backend-Health", true, ""},
+	{"{A}x-backend-health", true, ""},
+	{"{A}X-Missing", false, "missing"},
+	{"{A}X-Backend-Health: ok", false, `"degraded"`},
+	{"{A}X-Backend-Health: degraded", true, ""},
+	{"{A}Cache-Control:~no-cache", true, ""},
+	{"{A}Cache-Control:~max-age=\\d+", false, `"no-cache, no-store"`},
+}
+
+func TestExecuteTargetHeaderAssertions(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend-Health", "degraded")
+		w.Header().Set("Cache-Control", "no-cache, no-store")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	for _, tc := range testCasesForHeaderAssertions {
+		target, err := config.ParseTarget(testServer.URL + "|" + tc.assertion)
+		assert.Nil(t, err, tc.assertion)
+
+		result := ExecuteTarget(target)
+		assert.Nil(t, result.Error, tc.assertion)
+		assert.Equal(t, tc.success, result.Success(), tc.assertion)
+		assert.Contains(t, result.Assertion, tc.observed, tc.assertion)
+	}
+}
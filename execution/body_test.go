@@ -0,0 +1,51 @@
+package execution
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteTargetSendsBody(t *testing.T) {
+	var receivedBody, receivedContentType string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		receivedBody = string(b)
+		receivedContentType = r.Header.Get("Content-Type")
+		if len(b) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer testServer.Close()
+
+	var testCases = []struct {
+		target      string
+		body        string
+		contentType string
+	}{
+		{testServer.URL + `|POST|{D}'{"name": "asrt"}'`, `{"name": "asrt"}`, "application/json"},
+		{testServer.URL + `|POST|{D}'{"name": "asrt"}'|{H}Content-Type: application/vnd.asrt+json`, `{"name": "asrt"}`, "application/vnd.asrt+json"},
+		{testServer.URL + `|POST|{D}name=asrt`, "name=asrt", "application/x-www-form-urlencoded"},
+	}
+
+	for _, tc := range testCases {
+		target, err := config.ParseTarget(tc.target)
+		assert.Nil(t, err)
+
+		result := ExecuteTarget(target)
+		assert.True(t, result.Success(), tc.target)
+		assert.Equal(t, tc.body, receivedBody)
+		assert.Equal(t, tc.contentType, receivedContentType)
+	}
+
+	target, _ := config.ParseTarget(testServer.URL + "|POST")
+	result := ExecuteTarget(target)
+	assert.Equal(t, http.StatusBadRequest, result.Actual)
+	assert.Equal(t, "", receivedContentType)
+}
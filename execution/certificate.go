@@ -0,0 +1,50 @@
+package execution
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+)
+
+// newCertificateInfo describes the leaf certificate the server presented, or returns nil for plain connections.
+func newCertificateInfo(state *tls.ConnectionState, now time.Time) *output.CertificateInfo {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	return certificateInfoFromX509(state.PeerCertificates[0], now)
+}
+
+func certificateInfoFromX509(cert *x509.Certificate, now time.Time) *output.CertificateInfo {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return &output.CertificateInfo{
+		Subject:       cert.Subject.CommonName,
+		Issuer:        cert.Issuer.CommonName,
+		SANs:          sans,
+		NotAfter:      output.NewTimeStringForJSON(cert.NotAfter),
+		DaysRemaining: int(cert.NotAfter.Sub(now).Hours() / 24),
+		Expired:       now.After(cert.NotAfter),
+	}
+}
+
+// evaluateCertificateExpiry fails targets whose certificate has expired and warns when it expires
+// within the target's cert-warn-days. It does nothing unless cert-warn-days is set.
+func evaluateCertificateExpiry(target *config.Target, cert *output.CertificateInfo) (failure string, warning string) {
+	if target.CertWarnDays <= 0 || cert == nil {
+		return "", ""
+	}
+	if cert.Expired {
+		return fmt.Sprintf("certificate for %v expired on %v", cert.Subject, cert.NotAfter), ""
+	}
+	if cert.DaysRemaining < target.CertWarnDays {
+		return "", fmt.Sprintf("certificate for %v expires in %v days", cert.Subject, cert.DaysRemaining)
+	}
+	return "", ""
+}
@@ -0,0 +1,86 @@
+package execution
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteTargetCertificateInfo(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	target, _ := config.NewTarget("abc", testServer.URL, config.MethodGet, 200)
+	result := ExecuteTarget(target)
+
+	assert.True(t, result.Success())
+	assert.False(t, result.Warned())
+	assert.NotNil(t, result.Certificate)
+	assert.Contains(t, result.Certificate.SANs, "127.0.0.1")
+	assert.True(t, result.Certificate.DaysRemaining > 0)
+
+	target.CertWarnDays = 1000000
+	result = ExecuteTarget(target)
+
+	assert.True(t, result.Success())
+	assert.True(t, result.Warned())
+	assert.Contains(t, result.Warning, "certificate")
+}
+
+func TestExecuteTargetStrictTLS(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	target, _ := config.NewTarget("abc", testServer.URL, config.MethodGet, 200)
+	target.StrictTLS = true
+	result := ExecuteTarget(target)
+
+	assert.False(t, result.Success())
+	assert.NotNil(t, result.Error)
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	target := &config.Target{CertWarnDays: 30}
+
+	var testCases = []struct {
+		notAfter         time.Time
+		failure, warning bool
+	}{
+		{now.AddDate(0, 0, 90), false, false},
+		{now.AddDate(0, 0, 10), false, true},
+		{now.AddDate(0, 0, -1), true, false},
+	}
+
+	for _, tc := range testCases {
+		cert := &x509.Certificate{
+			Subject:     pkix.Name{CommonName: "example.com"},
+			Issuer:      pkix.Name{CommonName: "Example CA"},
+			DNSNames:    []string{"example.com", "www.example.com"},
+			IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+			NotAfter:    tc.notAfter,
+		}
+		info := certificateInfoFromX509(cert, now)
+		assert.Equal(t, "Example CA", info.Issuer)
+		assert.Equal(t, []string{"example.com", "www.example.com", "10.0.0.1"}, info.SANs)
+
+		failure, warning := evaluateCertificateExpiry(target, info)
+		assert.Equal(t, tc.failure, failure != "", tc.notAfter)
+		assert.Equal(t, tc.warning, warning != "", tc.notAfter)
+	}
+
+	failure, warning := evaluateCertificateExpiry(&config.Target{}, certificateInfoFromX509(&x509.Certificate{NotAfter: now.AddDate(0, 0, -1)}, now))
+	assert.Empty(t, failure)
+	assert.Empty(t, warning)
+}
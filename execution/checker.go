@@ -0,0 +1,100 @@
+package execution
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mkboudreau/asrt/config"
+)
+
+var registeredCheckers = make(map[string]Checker)
+
+func init() {
+	RegisterChecker("http", new(httpChecker))
+	RegisterChecker("https", new(httpChecker))
+}
+
+// Checker makes a single attempt at checking a target. The scheme of the target's URL selects the checker,
+// such as tcp for tcp://db:5432. Retries, fail-after and warn-after are applied to the results of every
+// checker, so a checker only reports what it found.
+//
+// A checker that does not get an HTTP status code reports ExpectedText and ActualText instead, and anything
+// else that failed as the Assertion.
+type Checker interface {
+	Check(target *config.Target) *ExecutionResult
+}
+
+// RegisterChecker makes the checker check targets whose URL has the scheme. It replaces any checker that
+// was registered for the scheme before.
+func RegisterChecker(scheme string, checker Checker) {
+	log.Println("Registering Checker:", scheme)
+	if checker != nil {
+		registeredCheckers[strings.ToLower(scheme)] = checker
+	}
+}
+
+// GetChecker returns the checker registered for the scheme.
+func GetChecker(scheme string) (Checker, bool) {
+	checker, ok := registeredCheckers[strings.ToLower(scheme)]
+	return checker, ok
+}
+
+// RegisteredSchemes lists the schemes that have a checker.
+func RegisteredSchemes() []string {
+	var schemes []string
+	for scheme := range registeredCheckers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// CheckerForTarget returns the checker for the scheme of the target's URL, or an error naming the schemes
+// that can be checked.
+func CheckerForTarget(target *config.Target) (Checker, error) {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return nil, err
+	}
+	checker, ok := GetChecker(u.Scheme)
+	if !ok {
+		return nil, fmt.Errorf("no checker for scheme %q. Valid schemes are %v", u.Scheme, RegisteredSchemes())
+	}
+	return checker, nil
+}
+
+// checkAttempt makes a single attempt with the checker of the target and applies its response time limits
+func checkAttempt(target *config.Target) *ExecutionResult {
+	checker, err := CheckerForTarget(target)
+	if err != nil {
+		return &ExecutionResult{
+			URL:      target.URL,
+			Method:   string(target.Method),
+			Expected: target.ExpectedStatus,
+			Accept:   target.AcceptStatuses,
+			Error:    err,
+		}
+	}
+
+	result := checker.Check(target)
+	if result.Error != nil || !result.statusMatches() || result.Assertion != "" {
+		return result
+	}
+	if target.FailAfter > 0 && result.Duration > target.FailAfter {
+		result.Assertion = fmt.Sprintf("response time %v exceeds fail-after %v", roundDuration(result.Duration), target.FailAfter)
+	} else if result.Warning == "" && target.WarnAfter > 0 && result.Duration > target.WarnAfter {
+		result.Warning = fmt.Sprintf("response time %v exceeds warn-after %v", roundDuration(result.Duration), target.WarnAfter)
+	}
+	return result
+}
+
+// httpChecker checks http and https targets
+type httpChecker struct {
+}
+
+func (hc *httpChecker) Check(target *config.Target) *ExecutionResult {
+	return executeAttempt(target)
+}
@@ -0,0 +1,89 @@
+package execution
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeChecker reports the state it was given, taking the given time
+type fakeChecker struct {
+	state    string
+	duration time.Duration
+}
+
+func (fc *fakeChecker) Check(target *config.Target) *ExecutionResult {
+	return &ExecutionResult{URL: target.URL, ExpectedText: "up", ActualText: fc.state, Duration: fc.duration}
+}
+
+func unregisterFakeChecker() {
+	delete(registeredCheckers, "fake")
+}
+
+func TestCheckerSelectedByScheme(t *testing.T) {
+	RegisterChecker("FAKE", &fakeChecker{state: "up"})
+	defer unregisterFakeChecker()
+	assert.Contains(t, RegisteredSchemes(), "fake")
+	assert.Contains(t, RegisteredSchemes(), "https")
+
+	target, _ := config.NewTarget("", "fake://somewhere:1234", config.MethodGet, 200)
+	result := ExecuteTarget(target)
+	assert.True(t, result.Success())
+	assert.Equal(t, "up", result.ExpectedString())
+	assert.Equal(t, "up", result.ActualString())
+}
+
+func TestCheckerTextMismatchFails(t *testing.T) {
+	RegisterChecker("FAKE", &fakeChecker{state: "down"})
+	defer unregisterFakeChecker()
+
+	target, _ := config.NewTarget("", "fake://somewhere", config.MethodGet, 200)
+	target.Retry.Attempts = 2
+	result := ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.Equal(t, 2, result.Attempts)
+	assert.Equal(t, []string{"status down (expected up)"}, result.Failures)
+}
+
+func TestCheckerResponseTimeLimits(t *testing.T) {
+	RegisterChecker("FAKE", &fakeChecker{state: "up", duration: 300 * time.Millisecond})
+	defer unregisterFakeChecker()
+
+	target, _ := config.NewTarget("", "fake://somewhere", config.MethodGet, 200)
+	target.WarnAfter = 100 * time.Millisecond
+	result := ExecuteTarget(target)
+	assert.True(t, result.Warned())
+	assert.Equal(t, "response time 300ms exceeds warn-after 100ms", result.Warning)
+
+	target.FailAfter = 200 * time.Millisecond
+	result = ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.Equal(t, "response time 300ms exceeds fail-after 200ms", result.Assertion)
+}
+
+func TestCheckerUnknownScheme(t *testing.T) {
+	target, _ := config.NewTarget("", "gopher://somewhere", config.MethodGet, 200)
+	_, err := CheckerForTarget(target)
+	assert.NotNil(t, err)
+
+	result := ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.Contains(t, result.Error.Error(), `no checker for scheme "gopher"`)
+}
+
+func TestExecutorWithChecker(t *testing.T) {
+	RegisterChecker("FAKE", &fakeChecker{state: "down"})
+	defer unregisterFakeChecker()
+
+	formatter := &testResultFormatter{}
+	exec := NewExecutor(false, false, false, formatter, new(bytes.Buffer), 1)
+	target, _ := config.NewTarget("db", "fake://somewhere", config.MethodGet, 200)
+
+	assert.Equal(t, 1, exec.Execute([]*config.Target{target}))
+	assert.Equal(t, "up", formatter.lastResult.Expected)
+	assert.Equal(t, "down", formatter.lastResult.Actual)
+	assert.False(t, formatter.lastResult.Success)
+}
@@ -0,0 +1,461 @@
+package execution
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+)
+
+// defaultDNSTimeout limits how long dns targets without a timeout wait for an answer
+const defaultDNSTimeout time.Duration = 5 * time.Second
+
+// resolvConfPath is where dns targets without a server find the resolver of the system
+var resolvConfPath = "/etc/resolv.conf"
+
+const (
+	dnsTypeA     uint16 = 1
+	dnsTypeNS           = 2
+	dnsTypeCNAME        = 5
+	dnsTypePTR          = 12
+	dnsTypeMX           = 15
+	dnsTypeTXT          = 16
+	dnsTypeAAAA         = 28
+	dnsTypeSRV          = 33
+)
+
+var dnsTypes = map[string]uint16{
+	"A":     dnsTypeA,
+	"NS":    dnsTypeNS,
+	"CNAME": dnsTypeCNAME,
+	"PTR":   dnsTypePTR,
+	"MX":    dnsTypeMX,
+	"TXT":   dnsTypeTXT,
+	"AAAA":  dnsTypeAAAA,
+	"SRV":   dnsTypeSRV,
+}
+
+var dnsResponseCodes = map[int]string{
+	0: "NOERROR",
+	1: "FORMERR",
+	2: "SERVFAIL",
+	3: "NXDOMAIN",
+	4: "NOTIMP",
+	5: "REFUSED",
+}
+
+const dnsNoError string = "NOERROR"
+
+func init() {
+	RegisterChecker("dns", new(dnsChecker))
+}
+
+// dnsChecker queries a resolver for the records of a name, such as
+// dns://api.internal?type=A&expect=10.0.0.5&server=10.0.0.2:53. The answer has to be NOERROR with at least
+// one record of the type, every expected answer and, with min, at least that many records. The type
+// defaults to A and the server to the first nameserver of /etc/resolv.conf. Names and addresses are
+// compared the way DNS compares them, so case, a trailing dot and how an IPv6 address is written do not matter.
+type dnsChecker struct {
+}
+
+// dnsQuery is what a dns target asks for
+type dnsQuery struct {
+	Name   string
+	Type   string
+	Server string
+	Expect []string
+	Min    int
+}
+
+// dnsRecord is an answer of the type that was asked for, with its data written the way dig shows it
+type dnsRecord struct {
+	Type uint16
+	Data string
+}
+
+func (dc *dnsChecker) Check(target *config.Target) *ExecutionResult {
+	result := &ExecutionResult{URL: target.URL, ExpectedText: dnsNoError}
+
+	query, err := parseDNSQuery(target.URL)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	start := time.Now()
+	deadline := start.Add(defaultDNSTimeout)
+	if target.Timeout > 0 {
+		deadline = start.Add(target.Timeout)
+	}
+	rcode, answers, err := resolveDNS(query, deadline)
+	result.Duration = time.Since(start)
+	result.Timings = &output.Timings{DNS: output.Duration(result.Duration), Total: output.Duration(result.Duration)}
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.ActualText = rcode
+	if rcode == dnsNoError {
+		result.Assertion = evaluateDNSAnswers(query, answers)
+	}
+	return result
+}
+
+func parseDNSQuery(rawURL string) (*dnsQuery, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	query := &dnsQuery{Name: u.Host, Type: "A"}
+	if query.Name == "" {
+		return nil, fmt.Errorf("dns targets need a name, such as dns://api.internal?type=A")
+	}
+
+	for key, values := range u.Query() {
+		value := values[len(values)-1]
+		switch strings.ToLower(key) {
+		case "type":
+			query.Type = strings.ToUpper(value)
+			if _, ok := dnsTypes[query.Type]; !ok {
+				return nil, fmt.Errorf("unknown dns record type %q. Valid types are %v", value, validDNSTypes())
+			}
+		case "server":
+			query.Server = value
+		case "expect":
+			for _, v := range values {
+				query.Expect = append(query.Expect, splitDNSList(v)...)
+			}
+		case "min":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("invalid dns min %q: must be a number of records", value)
+			}
+			query.Min = count
+		default:
+			return nil, fmt.Errorf("unknown dns parameter %q. Valid parameters are type, expect, min and server", key)
+		}
+	}
+
+	if query.Server == "" {
+		query.Server = systemDNSServer()
+	}
+	if _, _, err := net.SplitHostPort(query.Server); err != nil {
+		query.Server = net.JoinHostPort(strings.Trim(query.Server, "[]"), "53")
+	}
+	return query, nil
+}
+
+func splitDNSList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func validDNSTypes() []string {
+	var types []string
+	for t := range dnsTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// systemDNSServer is the first nameserver of resolv.conf, or the local resolver when there is none
+func systemDNSServer() string {
+	file, err := os.Open(resolvConfPath)
+	if err != nil {
+		return "127.0.0.1"
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return fields[1]
+		}
+	}
+	return "127.0.0.1"
+}
+
+// evaluateDNSAnswers describes why the answers do not satisfy the query, or returns "" when they do
+func evaluateDNSAnswers(query *dnsQuery, answers []dnsRecord) string {
+	var found []string
+	for _, a := range answers {
+		found = append(found, a.Data)
+	}
+
+	if len(found) == 0 {
+		return fmt.Sprintf("no %v records for %v", query.Type, query.Name)
+	}
+	if len(found) < query.Min {
+		return fmt.Sprintf("%d %v records for %v, expected at least %d: %v", len(found), query.Type, query.Name, query.Min, strings.Join(found, ","))
+	}
+	for _, expected := range query.Expect {
+		if !containsDNSAnswer(found, expected) {
+			return fmt.Sprintf("%v records for %v are %v, expected %v", query.Type, query.Name, strings.Join(found, ","), expected)
+		}
+	}
+	return ""
+}
+
+func containsDNSAnswer(answers []string, expected string) bool {
+	for _, a := range answers {
+		if normalizeDNSAnswer(a) == normalizeDNSAnswer(expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDNSAnswer(answer string) string {
+	if ip := net.ParseIP(answer); ip != nil {
+		return ip.String()
+	}
+	fields := strings.Fields(strings.ToLower(answer))
+	for i, f := range fields {
+		fields[i] = strings.TrimSuffix(f, ".")
+	}
+	return strings.Join(fields, " ")
+}
+
+// resolveDNS asks the server over udp, and again over tcp when the answer did not fit
+func resolveDNS(query *dnsQuery, deadline time.Time) (string, []dnsRecord, error) {
+	id := uint16(rand.Intn(1 << 16))
+	qtype := dnsTypes[query.Type]
+	message, err := buildDNSQuery(id, query.Name, qtype)
+	if err != nil {
+		return "", nil, err
+	}
+
+	response, err := exchangeDNS("udp", query.Server, message, deadline)
+	if err != nil {
+		return "", nil, err
+	}
+	rcode, truncated, answers, err := parseDNSResponse(response, id, qtype)
+	if err == nil && truncated {
+		if response, err = exchangeDNS("tcp", query.Server, message, deadline); err == nil {
+			rcode, _, answers, err = parseDNSResponse(response, id, qtype)
+		}
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	name, ok := dnsResponseCodes[rcode]
+	if !ok {
+		name = fmt.Sprintf("RCODE%d", rcode)
+	}
+	return name, answers, nil
+}
+
+func exchangeDNS(network string, server string, message []byte, deadline time.Time) ([]byte, error) {
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.Dial(network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline)
+
+	if network == "tcp" {
+		framed := make([]byte, 2+len(message))
+		binary.BigEndian.PutUint16(framed, uint16(len(message)))
+		copy(framed[2:], message)
+		if _, err := conn.Write(framed); err != nil {
+			return nil, err
+		}
+		var length uint16
+		if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		response := make([]byte, length)
+		_, err := io.ReadFull(conn, response)
+		return response, err
+	}
+
+	if _, err := conn.Write(message); err != nil {
+		return nil, err
+	}
+	response := make([]byte, 65535)
+	for {
+		n, err := conn.Read(response)
+		if err != nil {
+			return nil, err
+		}
+		// answers to other queries, such as late ones to an earlier attempt, have a different id
+		if n >= 2 && binary.BigEndian.Uint16(response) == binary.BigEndian.Uint16(message) {
+			return response[:n], nil
+		}
+	}
+}
+
+// buildDNSQuery creates a query message asking for recursion
+func buildDNSQuery(id uint16, name string, qtype uint16) ([]byte, error) {
+	message := make([]byte, 12)
+	binary.BigEndian.PutUint16(message[0:], id)
+	binary.BigEndian.PutUint16(message[2:], 0x0100) // recursion desired
+	binary.BigEndian.PutUint16(message[4:], 1)      // one question
+
+	encodedName, err := encodeDNSName(name)
+	if err != nil {
+		return nil, err
+	}
+	message = append(message, encodedName...)
+	message = append(message, byte(qtype>>8), byte(qtype), 0, 1) // class IN
+	return message, nil
+}
+
+func encodeDNSName(name string) ([]byte, error) {
+	var encoded []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, fmt.Errorf("invalid dns name %q", name)
+		}
+		encoded = append(encoded, byte(len(label)))
+		encoded = append(encoded, label...)
+	}
+	return append(encoded, 0), nil
+}
+
+var errDNSMessage = errors.New("malformed dns response")
+
+// parseDNSResponse returns the response code and the answers of the type that was asked for. CNAME records
+// that lead to the answers are left out, unless CNAME was asked for.
+func parseDNSResponse(message []byte, id uint16, qtype uint16) (int, bool, []dnsRecord, error) {
+	if len(message) < 12 || binary.BigEndian.Uint16(message) != id {
+		return 0, false, nil, errDNSMessage
+	}
+	flags := binary.BigEndian.Uint16(message[2:])
+	rcode := int(flags & 0x000f)
+	truncated := flags&0x0200 != 0
+	questions := int(binary.BigEndian.Uint16(message[4:]))
+	answerCount := int(binary.BigEndian.Uint16(message[6:]))
+
+	offset := 12
+	for i := 0; i < questions; i++ {
+		_, next, err := readDNSName(message, offset)
+		if err != nil {
+			return 0, false, nil, err
+		}
+		offset = next + 4
+	}
+
+	var answers []dnsRecord
+	for i := 0; i < answerCount; i++ {
+		_, next, err := readDNSName(message, offset)
+		if err != nil {
+			return 0, false, nil, err
+		}
+		if next+10 > len(message) {
+			return 0, false, nil, errDNSMessage
+		}
+		rtype := binary.BigEndian.Uint16(message[next:])
+		length := int(binary.BigEndian.Uint16(message[next+8:]))
+		start := next + 10
+		if start+length > len(message) {
+			return 0, false, nil, errDNSMessage
+		}
+		offset = start + length
+
+		if rtype != qtype {
+			continue
+		}
+		data, err := formatDNSData(message, rtype, start, length)
+		if err != nil {
+			return 0, false, nil, err
+		}
+		answers = append(answers, dnsRecord{Type: rtype, Data: data})
+	}
+	return rcode, truncated, answers, nil
+}
+
+// formatDNSData writes the data of a record the way dig shows it, without the trailing dot of names
+func formatDNSData(message []byte, rtype uint16, start int, length int) (string, error) {
+	data := message[start : start+length]
+	switch rtype {
+	case dnsTypeA, dnsTypeAAAA:
+		if len(data) != net.IPv4len && len(data) != net.IPv6len {
+			return "", errDNSMessage
+		}
+		return net.IP(data).String(), nil
+	case dnsTypeCNAME, dnsTypeNS, dnsTypePTR:
+		name, _, err := readDNSName(message, start)
+		return name, err
+	case dnsTypeMX:
+		if length < 3 {
+			return "", errDNSMessage
+		}
+		name, _, err := readDNSName(message, start+2)
+		return fmt.Sprintf("%d %v", binary.BigEndian.Uint16(data), name), err
+	case dnsTypeSRV:
+		if length < 7 {
+			return "", errDNSMessage
+		}
+		name, _, err := readDNSName(message, start+6)
+		return fmt.Sprintf("%d %d %d %v", binary.BigEndian.Uint16(data), binary.BigEndian.Uint16(data[2:]), binary.BigEndian.Uint16(data[4:]), name), err
+	case dnsTypeTXT:
+		var text []string
+		for i := 0; i < len(data); {
+			n := int(data[i])
+			if i+1+n > len(data) {
+				return "", errDNSMessage
+			}
+			text = append(text, string(data[i+1:i+1+n]))
+			i += 1 + n
+		}
+		return strings.Join(text, ""), nil
+	}
+	return "", fmt.Errorf("unsupported dns record type %d", rtype)
+}
+
+// readDNSName reads a possibly compressed name, and returns it along with the offset after it
+func readDNSName(message []byte, offset int) (string, int, error) {
+	var labels []string
+	next := -1
+	for jumps := 0; ; {
+		if offset >= len(message) {
+			return "", 0, errDNSMessage
+		}
+		length := int(message[offset])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = offset + 1
+			}
+			return strings.Join(labels, "."), next, nil
+		case length&0xc0 == 0xc0:
+			if offset+1 >= len(message) || jumps > 10 {
+				return "", 0, errDNSMessage
+			}
+			if next < 0 {
+				next = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(message[offset:]) & 0x3fff)
+			jumps++
+		default:
+			if offset+1+length > len(message) {
+				return "", 0, errDNSMessage
+			}
+			labels = append(labels, string(message[offset+1:offset+1+length]))
+			offset += 1 + length
+		}
+	}
+}
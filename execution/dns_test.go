@@ -0,0 +1,319 @@
+package execution
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDNSRecord struct {
+	Type  uint16
+	RData []byte
+}
+
+// testDNSZone answers by name and type. Names that are not in it get NXDOMAIN.
+type testDNSZone map[string][]testDNSRecord
+
+// testDNSServer answers queries from its zone over udp and tcp on the same port. With truncate, every udp
+// answer is empty and truncated, so that the client has to ask again over tcp.
+type testDNSServer struct {
+	zone     testDNSZone
+	truncate bool
+	silent   bool
+	udp      net.PacketConn
+	tcp      net.Listener
+}
+
+func startTestDNSServer(t *testing.T, s *testDNSServer) *testDNSServer {
+	for attempt := 0; s.tcp == nil; attempt++ {
+		udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+		if err != nil {
+			udp.Close()
+			if attempt > 10 {
+				t.Fatal(err)
+			}
+			continue
+		}
+		s.udp, s.tcp = udp, tcp
+	}
+
+	go s.serveUDP()
+	go s.serveTCP()
+	return s
+}
+
+func (s *testDNSServer) Address() string {
+	return s.udp.LocalAddr().String()
+}
+
+func (s *testDNSServer) Close() {
+	s.udp.Close()
+	s.tcp.Close()
+}
+
+func (s *testDNSServer) serveUDP() {
+	buffer := make([]byte, 512)
+	for {
+		n, addr, err := s.udp.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		if s.silent {
+			continue
+		}
+		s.udp.WriteTo(s.answer(buffer[:n], s.truncate), addr)
+	}
+}
+
+func (s *testDNSServer) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			return
+		}
+		var length uint16
+		if binary.Read(conn, binary.BigEndian, &length) == nil {
+			query := make([]byte, length)
+			if _, err := io.ReadFull(conn, query); err == nil {
+				response := s.answer(query, false)
+				binary.Write(conn, binary.BigEndian, uint16(len(response)))
+				conn.Write(response)
+			}
+		}
+		conn.Close()
+	}
+}
+
+func (s *testDNSServer) answer(query []byte, truncate bool) []byte {
+	name, next, _ := readDNSName(query, 12)
+	qtype := binary.BigEndian.Uint16(query[next:])
+	question := query[12 : next+4]
+
+	flags := uint16(0x8180) // response, recursion desired and available
+	records, ok := s.zone[strings.ToLower(name)]
+	if !ok {
+		flags |= 3 // NXDOMAIN
+	}
+	if truncate {
+		flags |= 0x0200
+		records = nil
+	}
+
+	var answers []testDNSRecord
+	for _, r := range records {
+		if r.Type == qtype || r.Type == dnsTypeCNAME {
+			answers = append(answers, r)
+		}
+	}
+
+	response := make([]byte, 12)
+	binary.BigEndian.PutUint16(response[0:], binary.BigEndian.Uint16(query))
+	binary.BigEndian.PutUint16(response[2:], flags)
+	binary.BigEndian.PutUint16(response[4:], 1)
+	binary.BigEndian.PutUint16(response[6:], uint16(len(answers)))
+	response = append(response, question...)
+	for _, r := range answers {
+		response = append(response, 0xc0, 12) // the name of the question
+		response = append(response, byte(r.Type>>8), byte(r.Type), 0, 1, 0, 0, 0, 60)
+		response = append(response, byte(len(r.RData)>>8), byte(len(r.RData)))
+		response = append(response, r.RData...)
+	}
+	return response
+}
+
+func dnsIP(address string) testDNSRecord {
+	ip := net.ParseIP(address)
+	if ip4 := ip.To4(); ip4 != nil {
+		return testDNSRecord{Type: dnsTypeA, RData: ip4}
+	}
+	return testDNSRecord{Type: dnsTypeAAAA, RData: ip}
+}
+
+func dnsName(rtype uint16, name string) testDNSRecord {
+	encoded, _ := encodeDNSName(name)
+	return testDNSRecord{Type: rtype, RData: encoded}
+}
+
+func dnsMX(preference uint16, name string) testDNSRecord {
+	encoded, _ := encodeDNSName(name)
+	return testDNSRecord{Type: dnsTypeMX, RData: append([]byte{byte(preference >> 8), byte(preference)}, encoded...)}
+}
+
+func dnsSRV(priority, weight, port uint16, name string) testDNSRecord {
+	encoded, _ := encodeDNSName(name)
+	rdata := []byte{byte(priority >> 8), byte(priority), byte(weight >> 8), byte(weight), byte(port >> 8), byte(port)}
+	return testDNSRecord{Type: dnsTypeSRV, RData: append(rdata, encoded...)}
+}
+
+func dnsTXT(texts ...string) testDNSRecord {
+	var rdata []byte
+	for _, text := range texts {
+		rdata = append(rdata, byte(len(text)))
+		rdata = append(rdata, text...)
+	}
+	return testDNSRecord{Type: dnsTypeTXT, RData: rdata}
+}
+
+var testZone = testDNSZone{
+	"api.internal":          {dnsIP("10.0.0.5"), dnsIP("10.0.0.6"), dnsIP("fd00::5")},
+	"www.internal":          {dnsName(dnsTypeCNAME, "api.internal"), dnsIP("10.0.0.5")},
+	"internal":              {dnsMX(10, "mail.internal"), dnsTXT("v=spf1 ", "-all"), dnsName(dnsTypeNS, "ns1.internal")},
+	"_sip._tcp.internal":    {dnsSRV(10, 5, 5060, "sip.internal")},
+	"5.0.0.10.in-addr.arpa": {dnsName(dnsTypePTR, "api.internal")},
+	"empty.internal":        {},
+	"only-v6.api.internal":  {dnsIP("fd00::7")},
+	"many.internal":         {dnsIP("10.0.1.1"), dnsIP("10.0.1.2"), dnsIP("10.0.1.3")},
+	"case.internal":         {dnsName(dnsTypeCNAME, "API.Internal")},
+}
+
+func dnsTarget(server *testDNSServer, query string) *config.Target {
+	separator := "?"
+	if strings.Contains(query, "?") {
+		separator = "&"
+	}
+	target, _ := config.NewTarget("", "dns://"+query+separator+"server="+server.Address(), config.MethodGet, 200)
+	return target
+}
+
+func TestDNSAnswers(t *testing.T) {
+	server := startTestDNSServer(t, &testDNSServer{zone: testZone})
+	defer server.Close()
+
+	for _, query := range []string{
+		"api.internal",
+		"api.internal?type=A&expect=10.0.0.5",
+		"api.internal?expect=10.0.0.6,10.0.0.5",
+		"api.internal?expect=10.0.0.5&expect=10.0.0.6",
+		"api.internal?type=aaaa&expect=fd00:0:0:0:0:0:0:5",
+		"api.internal?min=2",
+		"www.internal?expect=10.0.0.5",
+		"www.internal?type=CNAME&expect=api.internal.",
+		"case.internal?type=CNAME&expect=api.internal",
+		"internal?type=MX&expect=10 mail.internal",
+		"internal?type=TXT&expect=v=spf1 -all",
+		"internal?type=NS&expect=ns1.internal",
+		"_sip._tcp.internal?type=SRV&expect=10 5 5060 sip.internal",
+		"5.0.0.10.in-addr.arpa?type=PTR&expect=api.internal",
+	} {
+		result := ExecuteTarget(dnsTarget(server, query))
+		assert.Nil(t, result.Error, query)
+		assert.Empty(t, result.Assertion, query)
+		assert.True(t, result.Success(), query)
+		assert.Equal(t, "NOERROR", result.ActualString(), query)
+		assert.True(t, result.Timings.DNS > 0, query)
+	}
+}
+
+func TestDNSFailures(t *testing.T) {
+	server := startTestDNSServer(t, &testDNSServer{zone: testZone})
+	defer server.Close()
+
+	for _, tc := range []struct {
+		query     string
+		actual    string
+		assertion string
+	}{
+		{"missing.internal", "NXDOMAIN", ""},
+		{"api.internal?expect=10.0.0.9", "NOERROR", "A records for api.internal are 10.0.0.5,10.0.0.6, expected 10.0.0.9"},
+		{"api.internal?min=3", "NOERROR", "2 A records for api.internal, expected at least 3: 10.0.0.5,10.0.0.6"},
+		{"empty.internal", "NOERROR", "no A records for empty.internal"},
+		{"only-v6.api.internal", "NOERROR", "no A records for only-v6.api.internal"},
+		{"internal?type=MX&expect=20 mail.internal", "NOERROR", "MX records for internal are 10 mail.internal, expected 20 mail.internal"},
+	} {
+		result := ExecuteTarget(dnsTarget(server, tc.query))
+		assert.Nil(t, result.Error, tc.query)
+		assert.False(t, result.Success(), tc.query)
+		assert.Equal(t, tc.actual, result.ActualString(), tc.query)
+		assert.Equal(t, "NOERROR", result.ExpectedString(), tc.query)
+		assert.Equal(t, tc.assertion, result.Assertion, tc.query)
+	}
+}
+
+func TestDNSTruncatedAnswerOverTCP(t *testing.T) {
+	server := startTestDNSServer(t, &testDNSServer{zone: testZone, truncate: true})
+	defer server.Close()
+
+	result := ExecuteTarget(dnsTarget(server, "many.internal?min=3"))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+}
+
+func TestDNSTimeout(t *testing.T) {
+	server := startTestDNSServer(t, &testDNSServer{zone: testZone, silent: true})
+	defer server.Close()
+
+	target := dnsTarget(server, "api.internal")
+	target.Timeout = 100 * time.Millisecond
+	result := ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.NotNil(t, result.Error)
+}
+
+func TestDNSInvalidTargets(t *testing.T) {
+	for _, url := range []string{
+		"dns://api.internal?type=BOGUS",
+		"dns://api.internal?typo=A",
+		"dns://api.internal?min=some",
+		"dns://?type=A",
+	} {
+		target, _ := config.NewTarget("", url, config.MethodGet, 200)
+		result := ExecuteTarget(target)
+		assert.False(t, result.Success(), url)
+		assert.NotNil(t, result.Error, url)
+	}
+}
+
+func TestDNSServerFromResolvConf(t *testing.T) {
+	file, _ := ioutil.TempFile("", "resolv.conf")
+	defer os.Remove(file.Name())
+	file.WriteString("# generated\nsearch internal\nnameserver 10.0.0.2\nnameserver 10.0.0.3\n")
+	file.Close()
+
+	original := resolvConfPath
+	resolvConfPath = file.Name()
+	defer func() { resolvConfPath = original }()
+
+	query, err := parseDNSQuery("dns://api.internal")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2:53", query.Server)
+
+	query, err = parseDNSQuery("dns://api.internal?server=::1")
+	assert.Nil(t, err)
+	assert.Equal(t, "[::1]:53", query.Server)
+
+	query, err = parseDNSQuery("dns://api.internal?server=127.0.0.1:5353")
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:5353", query.Server)
+}
+
+func TestDNSMalformedResponses(t *testing.T) {
+	query, _ := buildDNSQuery(7, "api.internal", dnsTypeA)
+	response := append([]byte{}, query...)
+	response[2] = 0x81
+	binary.BigEndian.PutUint16(response[6:], 1)
+
+	// an answer whose name points at itself
+	loop := append(append([]byte{}, response...), 0xc0, byte(len(response)))
+	_, _, _, err := parseDNSResponse(loop, 7, dnsTypeA)
+	assert.NotNil(t, err)
+
+	// an answer that claims more data than there is
+	short := append(append([]byte{}, response...), 0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 10)
+	_, _, _, err = parseDNSResponse(short, 7, dnsTypeA)
+	assert.NotNil(t, err)
+
+	_, _, _, err = parseDNSResponse(response, 8, dnsTypeA)
+	assert.NotNil(t, err)
+}
@@ -1,15 +1,14 @@
 package execution
 
 import (
-	"fmt"
 	"io"
 	"log"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/mkboudreau/asrt/config"
 	"github.com/mkboudreau/asrt/output"
+	"github.com/mkboudreau/asrt/storage"
 	"github.com/mkboudreau/asrt/writer"
 )
 
@@ -20,9 +19,12 @@ type Executor struct {
 	ReportOnlyStateChanges bool
 	OutputFormatter        output.ResultFormatter
 	OutputWriter           io.Writer
-	lastestState           map[stateKey]stateValue
+	state                  *stateTracker
+	store                  storage.Store
 	autoclose              bool
 	resultChannel          chan *output.Result
+	hostLimiter            *hostLimiter
+	rateLimiter            *rateLimiter
 }
 
 func NewExecutor(isAggregate, onlyFailures, onlyStateChange bool, formatter output.ResultFormatter, writer io.Writer, workers int) *Executor {
@@ -33,7 +35,7 @@ func NewExecutor(isAggregate, onlyFailures, onlyStateChange bool, formatter outp
 		ReportOnlyStateChanges: onlyStateChange,
 		OutputFormatter:        formatter,
 		OutputWriter:           writer,
-		lastestState:           make(map[stateKey]stateValue),
+		state:                  newStateTracker(DefaultStatePolicy),
 		autoclose:              true,
 	}
 }
@@ -46,6 +48,32 @@ func (executor *Executor) SetNoAutoClose() {
 	executor.autoclose = false
 }
 
+// SetStatePolicy changes when results count as a change of state, for ReportOnlyStateChanges.
+func (executor *Executor) SetStatePolicy(policy StatePolicy) {
+	executor.state.policy = policy
+}
+
+// SetStore saves every result to the store, which also tells state changes apart across restarts.
+func (executor *Executor) SetStore(store storage.Store) {
+	executor.store = store
+	executor.state.history = store
+}
+
+// ForgetTargets drops what is known about the state of the targets, such as targets that were removed or
+// changed when reloading. It must not be called while Execute runs.
+func (executor *Executor) ForgetTargets(targets []*config.Target) {
+	for _, t := range targets {
+		executor.state.forget(t.URL, t.Label)
+	}
+}
+
+// SetLimits caps the requests running against any one host at once and the requests started per second
+// across all workers. Zero means no limit. The limits are shared by every call to Execute.
+func (executor *Executor) SetLimits(perHost int, requestsPerSecond float64) {
+	executor.hostLimiter = newHostLimiter(perHost)
+	executor.rateLimiter = newRateLimiter(requestsPerSecond)
+}
+
 func (executor Executor) Execute(incomingTargets []*config.Target) int {
 	if executor.resultChannel != nil {
 		if _, ok := <-executor.resultChannel; !ok {
@@ -59,10 +87,13 @@ func (executor Executor) Execute(incomingTargets []*config.Target) int {
 
 	go executor.processTargets(targetChannel, executor.resultChannel)
 
-	for _, target := range incomingTargets {
-		targetChannel <- target
-	}
-	close(targetChannel)
+	// feed the workers while results are read below, since a worker waits for its result to be read
+	go func() {
+		for _, target := range incomingTargets {
+			targetChannel <- target
+		}
+		close(targetChannel)
+	}()
 
 	if executor.ReportInAggregate {
 		return executor.processAggregatedResult(executor.resultChannel)
@@ -71,48 +102,78 @@ func (executor Executor) Execute(incomingTargets []*config.Target) int {
 	}
 }
 
+// processTargets runs the targets on a fixed pool of WorkerCount goroutines
 func (executor *Executor) processTargets(incomingTargets <-chan *config.Target, resultChannel chan<- *output.Result) {
 	var wg sync.WaitGroup
 
-	for t := range incomingTargets {
-		wg.Add(1)
-		go func(target *config.Target) {
-			execResult := ExecuteWithTimoutAndHeaders(string(target.Method), target.URL, target.Timeout, target.Headers, target.ExpectedStatus)
+	workers := executor.WorkerCount
+	if workers < 1 {
+		workers = 1
+	}
 
-			result := output.NewResult(execResult.Success(), execResult.Error, strconv.Itoa(execResult.Expected), strconv.Itoa(execResult.Actual), execResult.URL, target.Label)
-			if target.Extra != nil {
-				result.Extra = target.Extra
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			for target := range incomingTargets {
+				resultChannel <- executor.executeTarget(target)
 			}
-			result.Timestamp = output.NewTimeStringForJSON(time.Now())
-			resultChannel <- result
 			wg.Done()
-		}(t)
+		}()
 	}
 
 	wg.Wait()
 	close(resultChannel)
 }
 
+func (executor *Executor) executeTarget(target *config.Target) *output.Result {
+	execResult := retryTarget(target, executor.limitedAttempt)
+
+	result := output.NewResult(execResult.Success(), execResult.Error, execResult.ExpectedString(), execResult.ActualString(), execResult.URL, target.Label)
+	result.Group = target.Group
+	result.Tags = target.Tags
+	result.Assertion = execResult.Assertion
+	result.ResponseTime = output.Duration(execResult.Duration)
+	result.Timings = execResult.Timings
+	result.Certificate = execResult.Certificate
+	if len(execResult.Redirects) > 0 {
+		result.Redirects = execResult.Redirects
+		result.FinalURL = execResult.FinalURL
+	}
+	if execResult.Attempts > 1 {
+		result.Attempts = execResult.Attempts
+		result.Failures = execResult.Failures
+	}
+	if execResult.Warned() {
+		result.Warning = true
+		result.Assertion = execResult.Warning
+	}
+	if target.Extra != nil {
+		result.Extra = target.Extra
+	}
+	result.Timestamp = output.NewTimeStringForJSON(time.Now())
+	return result
+}
+
+// limitedAttempt holds a slot of the host only while the attempt runs, so that a target waiting to retry
+// does not keep other targets of its host waiting too
+func (executor *Executor) limitedAttempt(target *config.Target) *ExecutionResult {
+	executor.rateLimiter.Wait()
+	release := executor.hostLimiter.Acquire(hostOfTarget(target))
+	defer release()
+	return checkAttempt(target)
+}
+
 func (executor *Executor) processEachResult(resultChannel <-chan *output.Result) int {
 	exitStatus := 0
 	counter := 0
 	formatter := executor.OutputFormatter
 	w := executor.OutputWriter
 	for r := range resultChannel {
-		if r.Success && executor.ReportOnlyFailures {
-			executor.updateState(r)
+		changed := executor.state.Observe(r)
+		executor.save(r)
+		if !executor.reportable(r, changed) {
 			continue
 		}
-		if executor.ReportOnlyStateChanges {
-			if !executor.didStateChange(r) {
-				//no change... continue
-				log.Printf("no change with result")
-				executor.updateState(r)
-				continue
-			}
-			log.Printf("found change with result, sending to writer")
-			executor.updateState(r)
-		}
 
 		if counter == 0 {
 			writer.WriteToWriter(w, formatter.Header())
@@ -120,9 +181,7 @@ func (executor *Executor) processEachResult(resultChannel <-chan *output.Result)
 			writer.WriteToWriter(w, formatter.RecordSeparator())
 		}
 		reader := formatter.Reader(r)
-		if !r.Success {
-			exitStatus = 1
-		}
+		exitStatus = exitStatusForResult(exitStatus, r)
 		writer.WriteToWriter(w, reader)
 		counter++
 	}
@@ -137,19 +196,60 @@ func (executor *Executor) processEachResult(resultChannel <-chan *output.Result)
 	return exitStatus
 }
 
+// reportable is false for the results that ReportOnlyFailures and ReportOnlyStateChanges leave out
+func (executor *Executor) reportable(r *output.Result, changed bool) bool {
+	if r.Success && !r.Warning && !r.Flapping && executor.ReportOnlyFailures {
+		return false
+	}
+	if executor.ReportOnlyStateChanges {
+		if !changed {
+			//no change... continue
+			log.Printf("no change with result")
+			return false
+		}
+		log.Printf("found change with result, sending to writer")
+	}
+	return true
+}
+
+// writeResults writes results that were already observed and filtered, all at once
+func (executor *Executor) writeResults(results []*output.Result) {
+	formatter := executor.OutputFormatter
+	w := executor.OutputWriter
+	for i, r := range results {
+		if i == 0 {
+			writer.WriteToWriter(w, formatter.Header())
+		} else {
+			writer.WriteToWriter(w, formatter.RecordSeparator())
+		}
+		writer.WriteToWriter(w, formatter.Reader(r))
+	}
+	if len(results) > 0 {
+		writer.WriteToWriter(w, formatter.Footer())
+	}
+
+	if executor.autoclose {
+		writer.DoneWithWriter(w)
+	}
+}
+
 func (executor *Executor) processAggregatedResult(resultChannel <-chan *output.Result) int {
 	var results []*output.Result
 	exitStatus := 0
-	formatter := executor.OutputFormatter
-	w := executor.OutputWriter
 	for r := range resultChannel {
+		executor.save(r)
 		results = append(results, r)
-		if !r.Success {
-			exitStatus = 1
-		}
+		exitStatus = exitStatusForResult(exitStatus, r)
 	}
 
-	if !executor.ReportOnlyFailures || (exitStatus == 1 && executor.ReportOnlyFailures) {
+	executor.writeAggregate(results, exitStatus)
+	return exitStatus
+}
+
+func (executor *Executor) writeAggregate(results []*output.Result, exitStatus int) {
+	formatter := executor.OutputFormatter
+	w := executor.OutputWriter
+	if !executor.ReportOnlyFailures || (exitStatus != 0 && executor.ReportOnlyFailures) {
 		reader := formatter.AggregateReader(results)
 		writer.WriteToWriter(w, formatter.Header())
 		writer.WriteToWriter(w, reader)
@@ -159,57 +259,24 @@ func (executor *Executor) processAggregatedResult(resultChannel <-chan *output.R
 	if executor.autoclose {
 		writer.DoneWithWriter(w)
 	}
-
-	return exitStatus
 }
 
-func (executor *Executor) didStateChange(result *output.Result) bool {
-	k, v := extractStateKeyValueFromResult(result)
-
-	lv, ok := executor.lastestState[*k]
-	if !ok {
-		log.Printf("Target %v First State Capture: %v", k, v)
-		return true
+func (executor *Executor) save(result *output.Result) {
+	if executor.store == nil {
+		return
 	}
-
-	if *v != lv {
-		log.Printf("Target %v Changed from [ %v ] to [ %v ]", k, lv, *v)
-	} else {
-		log.Printf("Target %v No Change [ %v ] ", k, *v)
+	if err := executor.store.Save(storage.NewRecord(result, time.Now())); err != nil {
+		log.Printf("Could not save result of %v to history: %v", result.Url, err)
 	}
-
-	return *v != lv
-}
-
-func (executor *Executor) updateState(result *output.Result) {
-	k, v := extractStateKeyValueFromResult(result)
-	executor.lastestState[*k] = *v
 }
 
-type stateKey struct {
-	Url, Label string
-}
-
-func (s stateKey) String() string {
-	return fmt.Sprintf("Label: %v | URL: %v", s.Label, s.Url)
-}
-
-type stateValue struct {
-	Success  bool
-	Expected string
-	Actual   string
-}
-
-func (s stateValue) String() string {
-	return fmt.Sprintf("Success: %v | Expected: %v | Actual: %v", s.Success, s.Expected, s.Actual)
-}
-
-func extractStateKeyValueFromResult(result *output.Result) (*stateKey, *stateValue) {
-	k := &stateKey{Url: result.Url, Label: result.Label}
-	v := &stateValue{
-		Success:  result.Success,
-		Expected: result.Expected,
-		Actual:   result.Actual,
+// exitStatusForResult is 1 when any result failed, otherwise 2 when any result warned, otherwise 0
+func exitStatusForResult(currentExitStatus int, result *output.Result) int {
+	if !result.Success {
+		return 1
+	}
+	if result.Warning && currentExitStatus == 0 {
+		return 2
 	}
-	return k, v
+	return currentExitStatus
 }
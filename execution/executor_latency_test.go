@@ -0,0 +1,55 @@
+package execution
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorLatencyThresholds(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	var testCases = []struct {
+		warnAfter, failAfter time.Duration
+		exitCode             int
+		success, warning     bool
+	}{
+		{0, 0, 0, true, false},
+		{time.Minute, time.Minute, 0, true, false},
+		{10 * time.Millisecond, 0, 2, true, true},
+		{10 * time.Millisecond, time.Minute, 2, true, true},
+		{0, 10 * time.Millisecond, 1, false, false},
+		{5 * time.Millisecond, 10 * time.Millisecond, 1, false, false},
+	}
+
+	for _, tc := range testCases {
+		writer := new(bytes.Buffer)
+		formatter := &testResultFormatter{}
+		exec := NewExecutor(false, false, false, formatter, writer, 1)
+		target, _ := config.NewTarget("abc", testServer.URL, config.MethodGet, 200)
+		target.WarnAfter = tc.warnAfter
+		target.FailAfter = tc.failAfter
+
+		actualExitCode := exec.Execute([]*config.Target{target})
+		actualResult := formatter.lastResult
+
+		assert.Equal(t, tc.exitCode, actualExitCode)
+		assert.Equal(t, tc.success, actualResult.Success)
+		assert.Equal(t, tc.warning, actualResult.Warning)
+		assert.True(t, time.Duration(actualResult.ResponseTime) >= 50*time.Millisecond)
+		if tc.exitCode == 0 {
+			assert.Empty(t, actualResult.Assertion)
+		} else {
+			assert.Contains(t, actualResult.Assertion, "response time")
+		}
+	}
+}
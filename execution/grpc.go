@@ -0,0 +1,292 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+)
+
+const (
+	grpcServing     string = "SERVING"
+	grpcHealthCheck        = "/grpc.health.v1.Health/Check"
+)
+
+// grpcServingStatuses are the values of grpc.health.v1.HealthCheckResponse.ServingStatus
+var grpcServingStatuses = map[uint64]string{
+	0: "UNKNOWN",
+	1: grpcServing,
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+var grpcStatusCodes = map[string]string{
+	"1":  "CANCELLED",
+	"2":  "UNKNOWN",
+	"3":  "INVALID_ARGUMENT",
+	"4":  "DEADLINE_EXCEEDED",
+	"5":  "NOT_FOUND",
+	"6":  "ALREADY_EXISTS",
+	"7":  "PERMISSION_DENIED",
+	"8":  "RESOURCE_EXHAUSTED",
+	"9":  "FAILED_PRECONDITION",
+	"10": "ABORTED",
+	"11": "OUT_OF_RANGE",
+	"12": "UNIMPLEMENTED",
+	"13": "INTERNAL",
+	"14": "UNAVAILABLE",
+	"15": "DATA_LOSS",
+	"16": "UNAUTHENTICATED",
+}
+
+func init() {
+	RegisterChecker("grpc", new(grpcChecker))
+	RegisterChecker("grpcs", &grpcChecker{secure: true})
+}
+
+// grpcChecker calls grpc.health.v1.Health/Check for the service in the path of the target, such as
+// grpc://orders.internal:9090/orders.v1.Orders, or for the whole server when there is no path. grpc targets
+// speak HTTP/2 in the clear and need a port; grpcs targets speak it over TLS, report the certificate like
+// https targets and default to port 443. SERVING succeeds, UNKNOWN warns, and NOT_SERVING, SERVICE_UNKNOWN
+// and calls that fail with a grpc status, such as servers without the health service, fail. Headers are
+// sent as metadata.
+type grpcChecker struct {
+	secure bool
+}
+
+// grpcTransport is what http.Transport and http2.Transport have in common
+type grpcTransport interface {
+	http.RoundTripper
+	CloseIdleConnections()
+}
+
+func (gc *grpcChecker) Check(target *config.Target) *ExecutionResult {
+	result := &ExecutionResult{URL: target.URL, ExpectedText: grpcServing}
+
+	req, service, err := gc.buildRequest(target)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	tr, err := gc.transport(target)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	if target.FreshConnection {
+		defer tr.CloseIdleConnections()
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithDeadline(context.Background(), deadlineFor(target, start, defaultReplyTimeout))
+	defer cancel()
+	trace := newTimingTrace(start)
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace.ClientTrace()))
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	defer resp.Body.Close()
+	status, err := readHealthCheckResponse(resp)
+	done := time.Now()
+	result.Duration = done.Sub(start)
+	result.Timings = trace.Timings(done)
+	result.Certificate = newCertificateInfo(resp.TLS, done)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.ActualText = status
+	if status == grpcServingStatuses[0] {
+		// the server has not decided yet, which is neither success nor failure
+		result.ExpectedText = ""
+		if service == "" {
+			service = "the server"
+		}
+		result.Warning = fmt.Sprintf("health of %v is %v", service, status)
+	}
+	certFailure, certWarning := evaluateCertificateExpiry(target, result.Certificate)
+	if certFailure != "" {
+		result.Assertion = certFailure
+	} else if certWarning != "" && result.Warning == "" {
+		result.Warning = certWarning
+	}
+	return result
+}
+
+// transport speaks HTTP/2 over tls for grpcs and in the clear for grpc
+func (gc *grpcChecker) transport(target *config.Target) (grpcTransport, error) {
+	settings := newTransportSettings(target)
+	if !gc.secure {
+		return h2cTransportForSettings(settings, target.FreshConnection), nil
+	}
+	settings.HTTP2 = true
+	return transportForSettings(settings, target.FreshConnection)
+}
+
+// buildRequest posts a HealthCheckRequest for the service of the target to the health service on its host
+func (gc *grpcChecker) buildRequest(target *config.Target) (*http.Request, string, error) {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	if u.Hostname() == "" {
+		return nil, "", fmt.Errorf("%v targets need a host, such as %v://orders.internal:9090/orders.v1.Orders", u.Scheme, u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" && gc.secure {
+		host = net.JoinHostPort(u.Hostname(), defaultTLSPort)
+	} else if u.Port() == "" {
+		return nil, "", fmt.Errorf("%v targets need a port, such as %v://%v:9090", u.Scheme, u.Scheme, u.Hostname())
+	}
+
+	scheme := "http"
+	if gc.secure {
+		scheme = "https"
+	}
+	service := strings.Trim(u.Path, "/")
+	endpoint := &url.URL{Scheme: scheme, Host: host, Path: grpcHealthCheck}
+	req, err := http.NewRequest(http.MethodPost, endpoint.String(), bytes.NewReader(encodeHealthCheckRequest(service)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	for key, value := range target.Headers {
+		req.Header.Add(key, value)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if target.Timeout > 0 {
+		req.Header.Set("Grpc-Timeout", strconv.FormatInt(int64(target.Timeout/time.Millisecond), 10)+"m")
+	}
+	return req, service, nil
+}
+
+// encodeHealthCheckRequest frames a HealthCheckRequest, whose only field is the service name, as a grpc message
+func encodeHealthCheckRequest(service string) []byte {
+	var message []byte
+	if service != "" {
+		length := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(length, uint64(len(service)))
+		message = append(message, 1<<3|2) // field 1, length delimited
+		message = append(message, length[:n]...)
+		message = append(message, service...)
+	}
+
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+var errGRPCMessage = errors.New("malformed grpc health check response")
+
+// readHealthCheckResponse returns the serving status of the response, or an error when the call failed.
+// Servers that fail a call right away send the grpc status with the headers rather than as trailers.
+func readHealthCheckResponse(resp *http.Response) (string, error) {
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("not a grpc server: http status %d", resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/grpc") {
+		return "", fmt.Errorf("not a grpc server: content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxBodySize))
+	if err != nil {
+		return "", err
+	}
+
+	code, message := resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	if code == "" {
+		code, message = resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	}
+	if code != "0" {
+		return "", grpcStatusError(code, message)
+	}
+
+	if len(body) < 5 || body[0] != 0 || int(binary.BigEndian.Uint32(body[1:])) != len(body)-5 {
+		return "", errGRPCMessage
+	}
+	status, err := decodeHealthCheckResponse(body[5:])
+	if err != nil {
+		return "", err
+	}
+	if name, ok := grpcServingStatuses[status]; ok {
+		return name, nil
+	}
+	return strconv.FormatUint(status, 10), nil
+}
+
+func grpcStatusError(code string, message string) error {
+	name, ok := grpcStatusCodes[code]
+	if !ok {
+		name = "status " + code
+	}
+	if code == "" {
+		name = "no grpc status"
+	}
+	if unescaped, err := url.PathUnescape(message); err == nil {
+		message = unescaped
+	}
+	if message == "" {
+		return fmt.Errorf("grpc health check failed: %v", name)
+	}
+	return fmt.Errorf("grpc health check failed: %v: %v", name, message)
+}
+
+// decodeHealthCheckResponse reads the status, field 1, of a HealthCheckResponse. A response without it
+// has the default status, UNKNOWN.
+func decodeHealthCheckResponse(message []byte) (uint64, error) {
+	var status uint64
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return 0, errGRPCMessage
+		}
+		message = message[n:]
+
+		switch key & 7 {
+		case 0:
+			value, n := binary.Uvarint(message)
+			if n <= 0 {
+				return 0, errGRPCMessage
+			}
+			if key>>3 == 1 {
+				status = value
+			}
+			message = message[n:]
+		case 1, 5:
+			size := 8
+			if key&7 == 5 {
+				size = 4
+			}
+			if len(message) < size {
+				return 0, errGRPCMessage
+			}
+			message = message[size:]
+		case 2:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || uint64(len(message)-n) < length {
+				return 0, errGRPCMessage
+			}
+			message = message[n+int(length):]
+		default:
+			return 0, errGRPCMessage
+		}
+	}
+	return status, nil
+}
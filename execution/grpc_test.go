@@ -0,0 +1,174 @@
+package execution
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// healthServer answers grpc.health.v1.Health/Check with the status of the service, and with NOT_FOUND
+// for services it does not know, the way the health server of grpc-go does.
+type healthServer struct {
+	statuses map[string]uint64
+	metadata http.Header
+}
+
+func (hs *healthServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hs.metadata = r.Header
+	body, _ := ioutil.ReadAll(r.Body)
+	if r.URL.Path != grpcHealthCheck || r.Header.Get("Content-Type") != "application/grpc" || len(body) < 5 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var service string
+	if len(body) > 7 && body[5] == 1<<3|2 {
+		service = string(body[7 : 7+int(body[6])])
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	status, ok := hs.statuses[service]
+	if !ok {
+		w.Header().Set("Grpc-Status", "5")
+		w.Header().Set("Grpc-Message", "unknown%20service")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var message []byte
+	if status != 0 {
+		message = []byte{1 << 3, byte(status)}
+	}
+	frame := make([]byte, 5)
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(frame, message...))
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+}
+
+func startHealthServer(statuses map[string]uint64, secure bool) (*httptest.Server, *healthServer) {
+	health := &healthServer{statuses: statuses}
+	server := httptest.NewUnstartedServer(health)
+	if secure {
+		server.EnableHTTP2 = true
+		server.StartTLS()
+	} else {
+		server.Config.Handler = h2c.NewHandler(health, &http2.Server{})
+		server.Start()
+	}
+	return server, health
+}
+
+func grpcTarget(server *httptest.Server, scheme string, service string) *config.Target {
+	address := server.Listener.Addr().String()
+	target, _ := config.NewTarget("", scheme+"://"+address+service, config.MethodGet, 200)
+	return target
+}
+
+var testHealthStatuses = map[string]uint64{
+	"":                1,
+	"orders.v1.Order": 1,
+	"billing":         2,
+	"cache":           0,
+}
+
+func TestGRPCHealthStatuses(t *testing.T) {
+	server, _ := startHealthServer(testHealthStatuses, false)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		service string
+		actual  string
+		success bool
+		warning string
+	}{
+		{"", "SERVING", true, ""},
+		{"/orders.v1.Order", "SERVING", true, ""},
+		{"/billing", "NOT_SERVING", false, ""},
+		{"/cache", "UNKNOWN", true, "health of cache is UNKNOWN"},
+	} {
+		result := ExecuteTarget(grpcTarget(server, "grpc", tc.service))
+		assert.Nil(t, result.Error, tc.service)
+		assert.Equal(t, tc.success, result.Success(), tc.service)
+		assert.Equal(t, tc.actual, result.ActualString(), tc.service)
+		assert.Equal(t, tc.warning, result.Warning, tc.service)
+		assert.NotNil(t, result.Timings, tc.service)
+		assert.True(t, result.Duration > 0, tc.service)
+	}
+}
+
+func TestGRPCUnknownService(t *testing.T) {
+	server, _ := startHealthServer(testHealthStatuses, false)
+	defer server.Close()
+
+	result := ExecuteTarget(grpcTarget(server, "grpc", "/inventory"))
+	assert.False(t, result.Success())
+	assert.EqualError(t, result.Error, "grpc health check failed: NOT_FOUND: unknown service")
+}
+
+func TestGRPCHeadersAreMetadata(t *testing.T) {
+	server, health := startHealthServer(testHealthStatuses, false)
+	defer server.Close()
+
+	target := grpcTarget(server, "grpc", "")
+	target.Headers = map[string]string{"Authorization": "Bearer secret", "Content-Type": "application/json"}
+	result := ExecuteTarget(target)
+	assert.True(t, result.Success())
+	assert.Equal(t, "Bearer secret", health.metadata.Get("Authorization"))
+	assert.Equal(t, "application/grpc", health.metadata.Get("Content-Type"))
+}
+
+func TestGRPCOverTLS(t *testing.T) {
+	server, _ := startHealthServer(testHealthStatuses, true)
+	defer server.Close()
+
+	result := ExecuteTarget(grpcTarget(server, "grpcs", "/orders.v1.Order"))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.NotNil(t, result.Certificate)
+
+	// speaking in the clear to a TLS server fails
+	result = ExecuteTarget(grpcTarget(server, "grpc", "/orders.v1.Order"))
+	assert.NotNil(t, result.Error)
+}
+
+func TestGRPCAgainstHTTPServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	result := ExecuteTarget(grpcTarget(server, "grpc", ""))
+	assert.False(t, result.Success())
+	assert.NotNil(t, result.Error)
+}
+
+func TestGRPCNeedsPort(t *testing.T) {
+	target, _ := config.NewTarget("", "grpc://orders.internal/orders.v1.Order", config.MethodGet, 200)
+	result := ExecuteTarget(target)
+	assert.NotNil(t, result.Error)
+	assert.True(t, strings.Contains(result.Error.Error(), "need a port"))
+}
+
+func TestDecodeHealthCheckResponse(t *testing.T) {
+	for _, tc := range []struct {
+		message []byte
+		status  uint64
+		valid   bool
+	}{
+		{[]byte{}, 0, true},
+		{[]byte{0x08, 0x01}, 1, true},
+		{[]byte{0x12, 0x02, 'h', 'i', 0x08, 0x02}, 2, true}, // unknown fields are skipped
+		{[]byte{0x08}, 0, false},
+		{[]byte{0x12, 0x05, 'h'}, 0, false},
+	} {
+		status, err := decodeHealthCheckResponse(tc.message)
+		assert.Equal(t, tc.valid, err == nil, "%v", tc.message)
+		assert.Equal(t, tc.status, status, "%v", tc.message)
+	}
+}
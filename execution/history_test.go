@@ -0,0 +1,51 @@
+package execution
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorSavesHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asrt-history")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	store, err := storage.Open(dir, 0)
+	assert.Nil(t, err)
+	target, _ := config.NewTarget("abc", testServer.URL, config.MethodGet, 200)
+
+	// successes are saved even when only failures are reported
+	exec := NewExecutor(false, true, true, &testResultFormatter{}, new(bytes.Buffer), 1)
+	exec.SetStore(store)
+	exec.Execute([]*config.Target{target})
+	exec.Execute([]*config.Target{target})
+
+	records, err := store.Query(storage.Query{Url: testServer.URL})
+	assert.Nil(t, err)
+	assert.Len(t, records, 2)
+	assert.Nil(t, store.Close())
+
+	// a new executor picks up the state from history, so the same result is not a change
+	store, err = storage.Open(dir, 0)
+	assert.Nil(t, err)
+	defer store.Close()
+
+	formatter := &testResultFormatter{}
+	exec = NewExecutor(false, false, true, formatter, new(bytes.Buffer), 1)
+	exec.SetStore(store)
+	exec.Execute([]*config.Target{target})
+	assert.Nil(t, formatter.lastResult)
+}
@@ -1,11 +1,20 @@
 package execution
 
 import (
+	"compress/gzip"
 	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
-	"net"
 	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
 )
 
 var DefaultHeaders = map[string]string{
@@ -15,16 +24,74 @@ var DefaultHeaders = map[string]string{
 }
 var DefaultTimeout = 0 * time.Second
 
+// DefaultRetry is the retry policy of requests made with Execute and its variants.
+var DefaultRetry = config.RetryPolicy{}
+
+// MaxBodySize limits how much of a response body is read when a target has body assertions.
+var MaxBodySize int64 = 10 * 1024 * 1024
+
 type ExecutionResult struct {
 	URL      string
 	Method   string
 	Expected int
+	Accept   *config.StatusExpectation
 	Actual   int
-	Error    error
+	// ExpectedText and ActualText take the place of the status codes for checkers that do not get one,
+	// such as SERVING for a grpc health check. The result fails when both are set and differ.
+	ExpectedText string
+	ActualText   string
+	Assertion    string
+	Warning      string
+	Duration     time.Duration
+	Timings      *output.Timings
+	Certificate  *output.CertificateInfo
+	Redirects    []output.Redirect
+	FinalURL     string
+	Attempts     int
+	Failures     []string
+	Error        error
 }
 
 func (r *ExecutionResult) Success() bool {
-	return r.Error == nil && r.Expected == r.Actual
+	return r.Error == nil && r.statusMatches() && r.Assertion == ""
+}
+
+func (r *ExecutionResult) statusMatches() bool {
+	if r.hasText() {
+		return r.ExpectedText == "" || r.ActualText == r.ExpectedText
+	}
+	if r.Accept != nil {
+		return r.Accept.Matches(r.Actual)
+	}
+	return r.Expected == r.Actual
+}
+
+// ExpectedString is the status expectation as it was written, such as 200, 200,204 or 2xx.
+func (r *ExecutionResult) ExpectedString() string {
+	if r.hasText() {
+		return r.ExpectedText
+	}
+	if r.Accept != nil {
+		return r.Accept.String()
+	}
+	return strconv.Itoa(r.Expected)
+}
+
+// ActualString is the status code that was received, or the ActualText of checkers that do not get one.
+func (r *ExecutionResult) ActualString() string {
+	if r.hasText() {
+		return r.ActualText
+	}
+	return strconv.Itoa(r.Actual)
+}
+
+func (r *ExecutionResult) hasText() bool {
+	return r.ExpectedText != "" || r.ActualText != ""
+}
+
+// Warned is true when the target succeeded but crossed its warn-after threshold.
+func (r *ExecutionResult) Warned() bool {
+	return r.Success() && r.Warning != ""
 }
 
 func Execute(method string, url string, expectation int) *ExecutionResult {
@@ -40,68 +107,185 @@ func ExecuteWithTimeout(method string, url string, timeout time.Duration, expect
 }
 
 func ExecuteWithTimoutAndHeaders(method string, url string, timeout time.Duration, headers map[string]string, expectation int) *ExecutionResult {
-	statusCode, err := execute(method, url, timeout, headers)
+	return ExecuteTarget(&config.Target{
+		Method:         config.CommandMethod(method),
+		URL:            url,
+		Timeout:        timeout,
+		Headers:        headers,
+		ExpectedStatus: expectation,
+		Retry:          DefaultRetry,
+	})
+}
+
+// ExecuteTarget runs a single config.Target and evaluates its expected status and assertions, trying
+// again as often as its retry policy allows while it fails.
+func ExecuteTarget(target *config.Target) *ExecutionResult {
+	return retryTarget(target, checkAttempt)
+}
 
-	return &ExecutionResult{
-		URL:      url,
-		Method:   method,
-		Expected: expectation,
-		Actual:   statusCode,
+// retryTarget runs attempt until it succeeds or the retry policy of the target gives up, sleeping between
+// attempts outside of attempt
+func retryTarget(target *config.Target, attempt func(target *config.Target) *ExecutionResult) *ExecutionResult {
+	var failures []string
+	for attempts := 1; ; attempts++ {
+		result := attempt(target)
+		result.Attempts = attempts
+		if result.Success() || attempts >= target.Retry.MaxAttempts() {
+			result.Failures = failures
+			return result
+		}
+		failures = append(failures, result.failureReason())
+		time.Sleep(target.Retry.DelayAfter(attempts))
+	}
+}
+
+// failureReason describes why an attempt failed, for the results of targets that needed retries
+func (r *ExecutionResult) failureReason() string {
+	switch {
+	case r.Error != nil:
+		return r.Error.Error()
+	case !r.statusMatches():
+		return fmt.Sprintf("status %v (expected %v)", r.ActualString(), r.ExpectedString())
+	}
+	return r.Assertion
+}
+
+func executeAttempt(target *config.Target) *ExecutionResult {
+	resp, err := execute(target, hasBodyAssertions(target.Assertions))
+
+	result := &ExecutionResult{
+		URL:      target.URL,
+		Method:   string(target.Method),
+		Expected: target.ExpectedStatus,
+		Accept:   target.AcceptStatuses,
 		Error:    err,
 	}
+	if err == nil {
+		result.Actual = resp.StatusCode
+		result.Duration = resp.Duration
+		result.Timings = resp.Timings
+		result.Certificate = newCertificateInfo(resp.TLS, time.Now())
+		result.Redirects = resp.Redirects
+		result.FinalURL = resp.FinalURL
+		certFailure, certWarning := evaluateCertificateExpiry(target, result.Certificate)
+		if failed := evaluateAssertions(target.Assertions, resp); failed != nil {
+			result.Assertion = failed.String()
+		} else if urlFailure := evaluateFinalURL(target, resp.FinalURL); urlFailure != "" {
+			result.Assertion = urlFailure
+		} else if certFailure != "" {
+			result.Assertion = certFailure
+		} else if certWarning != "" {
+			result.Warning = certWarning
+		}
+	}
+
+	return result
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// response holds the parts of an http.Response needed after the connection is closed.
+type response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+	Timings    *output.Timings
+	TLS        *tls.ConnectionState
+	Redirects  []output.Redirect
+	FinalURL   string
 }
 
-func execute(method string, url string, timeout time.Duration, headers map[string]string) (int, error) {
-	req, reqErr := buildRequest(method, url, headers)
+func execute(target *config.Target, readBody bool) (*response, error) {
+	req, reqErr := buildRequest(target)
 	if reqErr != nil {
-		return 0, reqErr
+		return nil, reqErr
 	}
 
-	resp, respErr := connectWithInsecureCert(req, timeout)
+	start := time.Now()
+	trace := newTimingTrace(start)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.ClientTrace()))
+
+	redirects := newRedirectChain(target.Redirects)
+	resp, respErr := connect(req, target, redirects)
 	log.Println("Connecting")
 	log.Printf(" - Request: %+v/n", req)
 	log.Printf(" - Response: %+v\n", resp)
 	log.Printf(" - Error: %+v\n", respErr)
 	if respErr != nil {
-		return 0, respErr
+		return nil, respErr
 	}
 	if resp.Body != nil {
 		defer resp.Body.Close()
 	}
-	return resp.StatusCode, nil
+
+	r := &response{StatusCode: resp.StatusCode, Header: resp.Header, TLS: resp.TLS, Redirects: redirects.hops, FinalURL: resp.Request.URL.String()}
+	if readBody && resp.Body != nil {
+		body, err := readResponseBody(resp)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	} else if resp.Body != nil {
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, MaxBodySize))
+	}
+	done := time.Now()
+	r.Duration = done.Sub(start)
+	r.Timings = trace.Timings(done)
+	return r, nil
 }
 
-func buildRequest(method string, url string, headers map[string]string) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, nil)
+// readResponseBody reads up to MaxBodySize bytes, decompressing gzip bodies that the transport left alone
+// because the Accept-Encoding header was set explicitly.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	var reader io.Reader = resp.Body
+	if !resp.Uncompressed && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	return ioutil.ReadAll(io.LimitReader(reader, MaxBodySize))
+}
+
+func buildRequest(target *config.Target) (*http.Request, error) {
+	var body io.Reader
+	if target.Body != "" {
+		body = strings.NewReader(target.Body)
+	}
+	req, err := http.NewRequest(string(target.Method), target.URL, body)
 
 	if err != nil {
 		return nil, err
 	}
 
 	header := http.Header{}
-	for key, value := range headers {
+	for key, value := range target.Headers {
 		header.Add(key, value)
 	}
+	if target.Body != "" && target.BodyContentType != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", target.BodyContentType)
+	}
 	req.Header = header
 
 	return req, nil
 }
 
-func connectWithInsecureCert(req *http.Request, timeout time.Duration) (*http.Response, error) {
-	//tr := &http.Transport{
-	//	TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	//}
-	tr := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		Dial: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).Dial,
-		TLSHandshakeTimeout: 10 * time.Second,
-		TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+// connect skips certificate verification unless the target asks for strict TLS or gives a CA bundle
+func connect(req *http.Request, target *config.Target, redirects *redirectChain) (*http.Response, error) {
+	tr, err := transportForTarget(target)
+	if err != nil {
+		return nil, err
 	}
 
-	client := &http.Client{Transport: tr, Timeout: timeout}
+	client := &http.Client{Transport: tr, Timeout: target.Timeout, CheckRedirect: redirects.CheckRedirect}
 
 	return client.Do(req)
 }
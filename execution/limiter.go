@@ -0,0 +1,91 @@
+package execution
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+)
+
+// hostLimiter caps how many requests run against the same host at once. A nil hostLimiter does not limit.
+type hostLimiter struct {
+	perHost int
+	mutex   sync.Mutex
+	slots   map[string]chan struct{}
+}
+
+func newHostLimiter(perHost int) *hostLimiter {
+	if perHost <= 0 {
+		return nil
+	}
+	return &hostLimiter{perHost: perHost, slots: make(map[string]chan struct{})}
+}
+
+// Acquire blocks until the host has a free slot and returns the func that frees it again.
+func (l *hostLimiter) Acquire(host string) func() {
+	if l == nil {
+		return func() {}
+	}
+
+	l.mutex.Lock()
+	slot, ok := l.slots[host]
+	if !ok {
+		slot = make(chan struct{}, l.perHost)
+		l.slots[host] = slot
+	}
+	l.mutex.Unlock()
+
+	slot <- struct{}{}
+	return func() { <-slot }
+}
+
+// rateLimiter spaces requests evenly so no more than the given number start each second.
+// A nil rateLimiter does not limit.
+type rateLimiter struct {
+	interval time.Duration
+	mutex    sync.Mutex
+	next     time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
+
+// Wait blocks until the next request is allowed to start.
+func (l *rateLimiter) Wait() {
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mutex.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// hostOfTarget is the host:port the target connects to, used to share per-host limits. A dns target
+// connects to its resolver rather than to the name it looks up.
+func hostOfTarget(target *config.Target) string {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return target.URL
+	}
+	if u.Scheme == "dns" {
+		if query, err := parseDNSQuery(target.URL); err == nil {
+			return query.Server
+		}
+	}
+	return u.Host
+}
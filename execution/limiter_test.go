@@ -0,0 +1,155 @@
+package execution
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrencyServer records the most requests it saw in flight at once
+type concurrencyServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	inFlight int
+	peak     int
+	total    int
+}
+
+func newConcurrencyServer(delay time.Duration) *concurrencyServer {
+	s := &concurrencyServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.inFlight++
+		s.total++
+		if s.inFlight > s.peak {
+			s.peak = s.inFlight
+		}
+		s.mutex.Unlock()
+
+		time.Sleep(delay)
+
+		s.mutex.Lock()
+		s.inFlight--
+		s.mutex.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	return s
+}
+
+func targetsFor(t *testing.T, url string, count int) []*config.Target {
+	var targets []*config.Target
+	for i := 0; i < count; i++ {
+		target, err := config.NewTarget("", url, config.MethodGet, 200)
+		assert.Nil(t, err)
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+func TestExecutorHonorsWorkerCount(t *testing.T) {
+	server := newConcurrencyServer(50 * time.Millisecond)
+	defer server.Close()
+
+	exec := NewExecutor(false, false, false, &testResultFormatter{}, new(bytes.Buffer), 3)
+	exitStatus := exec.Execute(targetsFor(t, server.URL, 12))
+
+	assert.Equal(t, 0, exitStatus)
+	assert.Equal(t, 12, server.total)
+	assert.True(t, server.peak <= 3, "peak concurrency %v exceeds 3 workers", server.peak)
+	assert.True(t, server.peak > 1, "workers did not run in parallel")
+}
+
+func TestExecutorHostConcurrency(t *testing.T) {
+	server := newConcurrencyServer(20 * time.Millisecond)
+	defer server.Close()
+
+	exec := NewExecutor(false, false, false, &testResultFormatter{}, new(bytes.Buffer), 8)
+	exec.SetLimits(1, 0)
+	exec.Execute(targetsFor(t, server.URL, 6))
+
+	assert.Equal(t, 6, server.total)
+	assert.Equal(t, 1, server.peak)
+}
+
+func TestExecutorMaxRequestsPerSecond(t *testing.T) {
+	server := newConcurrencyServer(0)
+	defer server.Close()
+
+	exec := NewExecutor(false, false, false, &testResultFormatter{}, new(bytes.Buffer), 5)
+	exec.SetLimits(0, 20)
+
+	start := time.Now()
+	exec.Execute(targetsFor(t, server.URL, 5))
+	elapsed := time.Since(start)
+
+	assert.Equal(t, 5, server.total)
+	assert.True(t, elapsed >= 200*time.Millisecond, "5 requests at 20/s finished in %v", elapsed)
+}
+
+func TestLimitersAreOptional(t *testing.T) {
+	assert.Nil(t, newHostLimiter(0))
+	assert.Nil(t, newRateLimiter(0))
+
+	var hosts *hostLimiter
+	hosts.Acquire("example.com")()
+	var rate *rateLimiter
+	rate.Wait()
+}
+
+func TestHostOfTarget(t *testing.T) {
+	target, _ := config.NewTarget("", "https://example.com:8443/health", config.MethodGet, 200)
+	assert.Equal(t, "example.com:8443", hostOfTarget(target))
+
+	target, _ = config.NewTarget("", "dns://api.internal?server=10.0.0.2", config.MethodGet, 200)
+	assert.Equal(t, "10.0.0.2:53", hostOfTarget(target))
+	other, _ := config.NewTarget("", "dns://www.internal?type=AAAA&server=10.0.0.2:53", config.MethodGet, 200)
+	assert.Equal(t, hostOfTarget(target), hostOfTarget(other))
+
+	file, _ := ioutil.TempFile("", "resolv.conf")
+	defer os.Remove(file.Name())
+	file.WriteString("nameserver 10.0.0.3\n")
+	file.Close()
+	original := resolvConfPath
+	resolvConfPath = file.Name()
+	defer func() { resolvConfPath = original }()
+
+	target, _ = config.NewTarget("", "dns://api.internal", config.MethodGet, 200)
+	assert.Equal(t, "10.0.0.3:53", hostOfTarget(target))
+}
+
+func TestHostSlotIsFreeWhileRetrying(t *testing.T) {
+	server := newConcurrencyServer(0)
+	defer server.Close()
+
+	exec := NewExecutor(false, false, false, &testResultFormatter{}, new(bytes.Buffer), 2)
+	exec.SetLimits(1, 0)
+
+	failing, _ := config.NewTarget("", server.URL, config.MethodGet, 500)
+	failing.Retry = config.RetryPolicy{Attempts: 2, Backoff: config.BackoffFixed, Delay: time.Second}
+	done := make(chan *output.Result)
+	go func() { done <- exec.executeTarget(failing) }()
+
+	for first := false; !first; time.Sleep(5 * time.Millisecond) {
+		server.mutex.Lock()
+		first = server.total > 0
+		server.mutex.Unlock()
+	}
+
+	healthy, _ := config.NewTarget("", server.URL, config.MethodGet, 200)
+	start := time.Now()
+	assert.True(t, exec.executeTarget(healthy).Success)
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "waited %v for the host of a retrying target", time.Since(start))
+
+	result := <-done
+	assert.False(t, result.Success)
+	assert.Equal(t, 3, server.total)
+}
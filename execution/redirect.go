@@ -0,0 +1,45 @@
+package execution
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+)
+
+// redirectChain enforces a target's redirect policy and records every redirect that was followed.
+type redirectChain struct {
+	policy config.RedirectPolicy
+	hops   []output.Redirect
+}
+
+func newRedirectChain(policy config.RedirectPolicy) *redirectChain {
+	return &redirectChain{policy: policy}
+}
+
+// CheckRedirect is used as the http.Client's CheckRedirect. Returning http.ErrUseLastResponse hands the
+// redirect response itself back to the caller, so its status and Location header can be asserted.
+func (c *redirectChain) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if !c.policy.Follows() {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > c.policy.MaxHops() {
+		return fmt.Errorf("stopped after %d redirects", c.policy.MaxHops())
+	}
+
+	hop := output.Redirect{URL: via[len(via)-1].URL.String(), Location: req.URL.String()}
+	if req.Response != nil {
+		hop.StatusCode = req.Response.StatusCode
+	}
+	c.hops = append(c.hops, hop)
+	return nil
+}
+
+// evaluateFinalURL compares where the request ended up with the target's expected final url, if any
+func evaluateFinalURL(target *config.Target, finalURL string) string {
+	if target.FinalURL == "" || target.FinalURL == finalURL {
+		return ""
+	}
+	return fmt.Sprintf("final url %v (observed %v)", target.FinalURL, finalURL)
+}
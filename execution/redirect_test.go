@@ -0,0 +1,92 @@
+package execution
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRedirectTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRedirectsFollowedByDefault(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "/old|GET|200")
+	assert.Nil(t, err)
+
+	result := ExecuteTarget(target)
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.Equal(t, server.URL+"/new", result.FinalURL)
+	if assert.Len(t, result.Redirects, 2) {
+		assert.Equal(t, server.URL+"/old", result.Redirects[0].URL)
+		assert.Equal(t, http.StatusMovedPermanently, result.Redirects[0].StatusCode)
+		assert.Equal(t, server.URL+"/moved", result.Redirects[0].Location)
+		assert.Equal(t, http.StatusFound, result.Redirects[1].StatusCode)
+		assert.Equal(t, server.URL+"/new", result.Redirects[1].Location)
+	}
+}
+
+func TestRedirectsNotFollowed(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "/old|GET|301|{A}Location: /moved|{O}redirects=none")
+	assert.Nil(t, err)
+
+	result := ExecuteTarget(target)
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success(), result.Assertion)
+	assert.Equal(t, http.StatusMovedPermanently, result.Actual)
+	assert.Empty(t, result.Redirects)
+	assert.Equal(t, server.URL+"/old", result.FinalURL)
+}
+
+func TestRedirectsMaxHops(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "/old|GET|200|{O}redirects=1")
+	assert.Nil(t, err)
+
+	result := ExecuteTarget(target)
+	assert.NotNil(t, result.Error)
+	assert.Contains(t, result.Error.Error(), "stopped after 1 redirects")
+	assert.False(t, result.Success())
+
+	target, err = config.ParseTarget(server.URL + "/old|GET|200|{O}redirects=2")
+	assert.Nil(t, err)
+	assert.True(t, ExecuteTarget(target).Success())
+}
+
+func TestRedirectsFinalURL(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "/old|GET|200|{O}final-url=" + server.URL + "/new")
+	assert.Nil(t, err)
+	result := ExecuteTarget(target)
+	assert.True(t, result.Success(), result.Assertion)
+
+	target, err = config.ParseTarget(server.URL + "/old|GET|200|{O}final-url=" + server.URL + "/elsewhere")
+	assert.Nil(t, err)
+	result = ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.Equal(t, "final url "+server.URL+"/elsewhere (observed "+server.URL+"/new)", result.Assertion)
+}
@@ -0,0 +1,94 @@
+package execution
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFlakyServer fails the first failures requests, alternating between a dropped connection and a 503
+func newFlakyServer(failures int) (*httptest.Server, func() int) {
+	var mutex sync.Mutex
+	requests := 0
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests++
+		n := requests
+		mutex.Unlock()
+
+		switch {
+		case n > failures:
+			w.WriteHeader(http.StatusOK)
+		case n%2 == 1:
+			hijacker := w.(http.Hijacker)
+			conn, _, _ := hijacker.Hijack()
+			conn.Close()
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	return server, func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests
+	}
+}
+
+func TestExecuteTargetRetriesUntilSuccess(t *testing.T) {
+	server, requests := newFlakyServer(2)
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "|GET|200|{O}attempts=3|{O}retry-delay=10ms")
+	assert.Nil(t, err)
+
+	result := ExecuteTarget(target)
+	assert.True(t, result.Success())
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, 3, requests())
+	if assert.Len(t, result.Failures, 2) {
+		assert.Contains(t, result.Failures[0], "EOF")
+		assert.Equal(t, "status 503 (expected 200)", result.Failures[1])
+	}
+}
+
+func TestExecuteTargetGivesUpAfterAttempts(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "|GET|200|{O}attempts=3|{O}backoff=exponential|{O}retry-delay=20ms")
+	assert.Nil(t, err)
+
+	start := time.Now()
+	result := ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.Equal(t, 503, result.Actual)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, []string{"status 503 (expected 200)", "status 503 (expected 200)"}, result.Failures)
+	assert.True(t, time.Since(start) >= 60*time.Millisecond, "expected 20ms then 40ms of backoff")
+}
+
+func TestExecuteTargetWithoutRetries(t *testing.T) {
+	server, requests := newFlakyServer(1)
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "|GET|200")
+	assert.Nil(t, err)
+
+	result := ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, 1, requests())
+	assert.Empty(t, result.Failures)
+}
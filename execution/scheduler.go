@@ -0,0 +1,307 @@
+package execution
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+)
+
+// Scheduler checks every target on its own Interval, each check delayed by a random part of up to the
+// target's Jitter, instead of checking all targets at once. Due targets are queued for one pool of the
+// executor's WorkerCount workers, and each target is scheduled again as soon as its own result is back.
+// Results are observed and saved like those of Execute, and results that are back together are reported
+// together. The report shows the latest result of every target, so that a target checked every 5m is still
+// reported next to one checked every 10s. A target with an Interval of 0 is only checked once.
+//
+// The state of the executor is only touched by Run, so ForgetTargets may be called from its reload function.
+type Scheduler struct {
+	executor *Executor
+	targets  []*config.Target
+	entries  map[stateKey]*scheduleEntry
+	queue    []*scheduledCheck // due, waiting for a free worker
+	checks   chan *scheduledCheck
+	finished chan *scheduledCheck
+	now      func() time.Time
+	jitter   func(max time.Duration) time.Duration
+}
+
+type scheduleEntry struct {
+	target  *config.Target
+	next    time.Time
+	idle    bool // checked once, and not scheduled again because the interval is 0
+	running bool // queued or being checked
+	latest  *output.Result
+}
+
+type scheduledCheck struct {
+	entry   *scheduleEntry
+	target  *config.Target // as it was when it came due, as SetTargets may replace that of the entry
+	started time.Time
+	result  *output.Result
+}
+
+// NewScheduler schedules the targets to be checked as soon as Run starts.
+func NewScheduler(executor *Executor, targets []*config.Target) *Scheduler {
+	s := &Scheduler{
+		executor: executor,
+		entries:  make(map[stateKey]*scheduleEntry),
+		checks:   make(chan *scheduledCheck),
+		finished: make(chan *scheduledCheck),
+		now:      time.Now,
+		jitter:   randomJitter,
+	}
+	s.SetTargets(targets)
+	return s
+}
+
+// SetTargets replaces the scheduled targets. Targets that did not change keep their schedule and latest
+// result. New and changed targets are due right away. Removed or changed targets that are still queued are
+// not checked, and results of those that are being checked are dropped.
+func (s *Scheduler) SetTargets(targets []*config.Target) {
+	entries := make(map[stateKey]*scheduleEntry)
+	for _, t := range targets {
+		k := targetKey(t)
+		if e, ok := s.entries[k]; ok && e.target.Equal(t) {
+			e.target = t
+			entries[k] = e
+			continue
+		}
+		entries[k] = &scheduleEntry{target: t, next: s.now()}
+	}
+	s.targets = targets
+	s.entries = entries
+
+	var queue []*scheduledCheck
+	for _, c := range s.queue {
+		if s.current(c.entry) {
+			queue = append(queue, c)
+		}
+	}
+	s.queue = queue
+}
+
+// Run checks the targets as they come due until stop is closed. beforeReport, when not nil, is called before
+// each report is written, such as to clear the console. When reload receives, the targets returned by
+// reloadTargets replace the scheduled ones.
+func (s *Scheduler) Run(stop <-chan struct{}, reload <-chan struct{}, reloadTargets func() []*config.Target, beforeReport func()) {
+	s.work(stop)
+	s.start()
+	for {
+		var due <-chan time.Time
+		if wait, ok := s.wait(); ok {
+			due = time.After(wait)
+		}
+		dispatch, next := s.next()
+
+		select {
+		case <-due:
+			s.start()
+		case dispatch <- next:
+			s.queue = s.queue[1:]
+		case check := <-s.finished:
+			changed := make(map[*output.Result]bool)
+			s.finish(check, changed)
+			for more := true; more; {
+				select {
+				case check := <-s.finished:
+					s.finish(check, changed)
+				default:
+					more = false
+				}
+			}
+			if beforeReport != nil {
+				beforeReport()
+			}
+			s.report(changed)
+			s.start()
+		case <-reload:
+			s.SetTargets(reloadTargets())
+			if s.start() == 0 {
+				// nothing new to check, but removed targets should no longer be reported
+				if beforeReport != nil {
+					beforeReport()
+				}
+				s.report(nil)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// CheckDue checks the targets that are due and reports their results once all of them are back, such as
+// to have a report before Run. It must not be called while Run is running.
+func (s *Scheduler) CheckDue() {
+	if s.start() == 0 {
+		return
+	}
+	_, changed := s.checkQueued()
+	s.report(changed)
+}
+
+// checkQueued hands the queued checks to a pool of its own until all of them are back, and returns the
+// entries that were checked and the results that finish added to changed
+func (s *Scheduler) checkQueued() (map[*scheduleEntry]bool, map[*output.Result]bool) {
+	stop := make(chan struct{})
+	defer close(stop)
+	s.work(stop)
+
+	checked := make(map[*scheduleEntry]bool)
+	changed := make(map[*output.Result]bool)
+	for running := 0; running > 0 || len(s.queue) > 0; {
+		dispatch, next := s.next()
+		select {
+		case dispatch <- next:
+			s.queue = s.queue[1:]
+			running++
+		case check := <-s.finished:
+			running--
+			checked[check.entry] = true
+			s.finish(check, changed)
+		}
+	}
+	return checked, changed
+}
+
+// work starts the workers that check what is sent on checks, until stop is closed
+func (s *Scheduler) work(stop <-chan struct{}) {
+	workers := s.executor.WorkerCount
+	if workers < 1 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case check := <-s.checks:
+					check.result = s.executor.executeTarget(check.target)
+					select {
+					case s.finished <- check:
+					case <-stop:
+						return
+					}
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+}
+
+// start queues the targets that are due and returns how many there are
+func (s *Scheduler) start() int {
+	now := s.now()
+	count := 0
+	for _, t := range s.targets {
+		e := s.entries[targetKey(t)]
+		if e.running || e.idle || e.next.After(now) {
+			continue
+		}
+		e.running = true
+		s.queue = append(s.queue, &scheduledCheck{entry: e, target: e.target, started: now})
+		count++
+	}
+	return count
+}
+
+// next is the channel to hand the first queued check to a worker on, which is nil when nothing is queued
+func (s *Scheduler) next() (chan<- *scheduledCheck, *scheduledCheck) {
+	if len(s.queue) == 0 {
+		return nil, nil
+	}
+	return s.checks, s.queue[0]
+}
+
+// wait is the time until the next target not being checked is due. It is false when no target is scheduled.
+func (s *Scheduler) wait() (time.Duration, bool) {
+	var next time.Time
+	for _, e := range s.entries {
+		if e.running || e.idle {
+			continue
+		}
+		if next.IsZero() || e.next.Before(next) {
+			next = e.next
+		}
+	}
+	if next.IsZero() {
+		return 0, false
+	}
+	if wait := next.Sub(s.now()); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+// finish schedules the next check of the target of the check, then observes and saves its result, which is
+// added to changed with whether it changed the state of the target
+func (s *Scheduler) finish(check *scheduledCheck, changed map[*output.Result]bool) {
+	e := check.entry
+	e.running = false
+	if e.target.Interval <= 0 {
+		e.idle = true
+	} else {
+		e.next = check.started.Add(e.target.Interval + s.jitter(e.target.Jitter))
+	}
+
+	if !s.current(e) {
+		// the target was removed or changed while it was being checked
+		return
+	}
+	r := check.result
+	changed[r] = s.executor.state.Observe(r)
+	s.executor.save(r)
+	e.latest = r
+}
+
+// current reports whether the entry is still scheduled, rather than removed or replaced by a changed target
+func (s *Scheduler) current(e *scheduleEntry) bool {
+	return s.entries[targetKey(e.target)] == e
+}
+
+// report writes the latest result of every target. Only the results in changed were just checked, and
+// whether they changed the state of their target decides if they are reported when ReportOnlyStateChanges.
+func (s *Scheduler) report(changed map[*output.Result]bool) int {
+	var latest []*output.Result
+	exitStatus := 0
+	seen := make(map[*scheduleEntry]bool)
+	for _, t := range s.targets {
+		e := s.entries[targetKey(t)]
+		if e.latest != nil && !seen[e] {
+			seen[e] = true
+			latest = append(latest, e.latest)
+			exitStatus = exitStatusForResult(exitStatus, e.latest)
+		}
+	}
+
+	if s.executor.ReportInAggregate {
+		s.executor.writeAggregate(latest, exitStatus)
+		return exitStatus
+	}
+
+	var reported []*output.Result
+	for _, r := range latest {
+		isChanged, fresh := changed[r]
+		if s.executor.ReportOnlyStateChanges && !fresh {
+			continue
+		}
+		if s.executor.reportable(r, isChanged) {
+			reported = append(reported, r)
+		}
+	}
+	s.executor.writeResults(reported)
+	return exitStatus
+}
+
+func targetKey(t *config.Target) stateKey {
+	return stateKey{Url: t.URL, Label: t.Label}
+}
+
+func randomJitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,396 @@
+package execution
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+	"github.com/stretchr/testify/assert"
+)
+
+// labelFormatter writes the label of every result on a line of its own
+type labelFormatter struct{}
+
+func (f *labelFormatter) Reader(result *output.Result) io.Reader {
+	return strings.NewReader(result.Label + "\n")
+}
+func (f *labelFormatter) AggregateReader(results []*output.Result) io.Reader {
+	return strings.NewReader("aggregate\n")
+}
+func (f *labelFormatter) Header() io.Reader          { return strings.NewReader("") }
+func (f *labelFormatter) Footer() io.Reader          { return strings.NewReader("") }
+func (f *labelFormatter) RecordSeparator() io.Reader { return strings.NewReader("") }
+
+// reportRecorder keeps what was written between each close, which the executor does after every report
+type reportRecorder struct {
+	buffer  bytes.Buffer
+	reports []string
+}
+
+func (r *reportRecorder) Write(p []byte) (int, error) {
+	return r.buffer.Write(p)
+}
+
+func (r *reportRecorder) Close() error {
+	r.reports = append(r.reports, r.buffer.String())
+	r.buffer.Reset()
+	return nil
+}
+
+func (r *reportRecorder) last() string {
+	if len(r.reports) == 0 {
+		return ""
+	}
+	return r.reports[len(r.reports)-1]
+}
+
+// testClock is a clock for the scheduler that only moves when told to
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestScheduler(executor *Executor, clock *testClock, targets ...*config.Target) *Scheduler {
+	s := NewScheduler(executor, nil)
+	s.now = clock.Now
+	s.jitter = func(time.Duration) time.Duration { return 0 }
+	s.SetTargets(targets)
+	return s
+}
+
+func scheduledTarget(label string, url string, interval time.Duration) *config.Target {
+	target, _ := config.NewTarget(label, url, config.MethodGet, 200)
+	target.Interval = interval
+	return target
+}
+
+// runDue checks the targets that are due and reports, returning the labels of the targets that were checked
+func runDue(s *Scheduler) []string {
+	if s.start() == 0 {
+		return nil
+	}
+	checked, changed := s.checkQueued()
+	s.report(changed)
+
+	var labels []string
+	for _, t := range s.targets {
+		if checked[s.entries[targetKey(t)]] {
+			labels = append(labels, t.Label)
+		}
+	}
+	return labels
+}
+
+func TestSchedulerIntervals(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	recorder := &reportRecorder{}
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(false, false, false, &labelFormatter{}, recorder, 2)
+	s := newTestScheduler(executor, clock,
+		scheduledTarget("login", testServer.URL+"/login", 10*time.Second),
+		scheduledTarget("report", testServer.URL+"/report", 5*time.Minute))
+
+	assert.Equal(t, []string{"login", "report"}, runDue(s))
+	assert.Equal(t, "login\nreport\n", recorder.last())
+
+	wait, ok := s.wait()
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, wait)
+
+	clock.now = clock.now.Add(10 * time.Second)
+	assert.Equal(t, []string{"login"}, runDue(s))
+	// the report still shows the latest result of the target that was not checked again
+	assert.Equal(t, "login\nreport\n", recorder.last())
+
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Nil(t, runDue(s))
+
+	clock.now = clock.now.Add(5 * time.Minute)
+	assert.Equal(t, []string{"login", "report"}, runDue(s))
+	assert.Len(t, recorder.reports, 3)
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(false, false, false, &labelFormatter{}, &reportRecorder{}, 1)
+	target := scheduledTarget("login", testServer.URL, 10*time.Second)
+	target.Jitter = 3 * time.Second
+	s := newTestScheduler(executor, clock, target)
+
+	var maxJitter time.Duration
+	s.jitter = func(max time.Duration) time.Duration {
+		maxJitter = max
+		return 2 * time.Second
+	}
+
+	runDue(s)
+	assert.Equal(t, 3*time.Second, maxJitter)
+	wait, _ := s.wait()
+	assert.Equal(t, 12*time.Second, wait)
+}
+
+func TestSchedulerZeroIntervalChecksOnce(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(false, false, false, &labelFormatter{}, &reportRecorder{}, 1)
+	s := newTestScheduler(executor, clock, scheduledTarget("once", testServer.URL, 0))
+
+	assert.Equal(t, []string{"once"}, runDue(s))
+	_, ok := s.wait()
+	assert.False(t, ok)
+
+	clock.now = clock.now.Add(time.Hour)
+	assert.Nil(t, runDue(s))
+}
+
+func TestSchedulerStateChangesOnly(t *testing.T) {
+	status := http.StatusOK
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/flaky" {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	recorder := &reportRecorder{}
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(false, false, true, &labelFormatter{}, recorder, 1)
+	s := newTestScheduler(executor, clock,
+		scheduledTarget("flaky", testServer.URL+"/flaky", 10*time.Second),
+		scheduledTarget("stable", testServer.URL+"/stable", 10*time.Second))
+
+	runDue(s)
+	assert.Equal(t, "flaky\nstable\n", recorder.last())
+
+	clock.now = clock.now.Add(10 * time.Second)
+	runDue(s)
+	assert.Equal(t, "", recorder.last())
+
+	status = http.StatusInternalServerError
+	clock.now = clock.now.Add(10 * time.Second)
+	runDue(s)
+	assert.Equal(t, "flaky\n", recorder.last())
+}
+
+func TestSchedulerAggregatesLatestResults(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	formatter := &testResultFormatter{}
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(true, false, false, formatter, new(bytes.Buffer), 1)
+	s := newTestScheduler(executor, clock,
+		scheduledTarget("fast", testServer.URL+"/fast", time.Second),
+		scheduledTarget("slow", testServer.URL+"/slow", time.Minute))
+
+	runDue(s)
+	clock.now = clock.now.Add(time.Second)
+	assert.Equal(t, []string{"fast"}, runDue(s))
+	assert.Len(t, formatter.lastAggregateResult, 2)
+}
+
+func TestSchedulerSetTargets(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	recorder := &reportRecorder{}
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(false, false, false, &labelFormatter{}, recorder, 1)
+	s := newTestScheduler(executor, clock,
+		scheduledTarget("kept", testServer.URL+"/kept", time.Minute),
+		scheduledTarget("changed", testServer.URL+"/changed", time.Minute),
+		scheduledTarget("removed", testServer.URL+"/removed", time.Minute))
+	runDue(s)
+
+	clock.now = clock.now.Add(time.Second)
+	s.SetTargets([]*config.Target{
+		scheduledTarget("kept", testServer.URL+"/kept", time.Minute),
+		scheduledTarget("changed", testServer.URL+"/changed", 10*time.Second),
+		scheduledTarget("added", testServer.URL+"/added", time.Minute),
+	})
+
+	assert.Equal(t, []string{"changed", "added"}, runDue(s))
+	assert.Equal(t, "kept\nchanged\nadded\n", recorder.last())
+}
+
+func TestSchedulerDropsResultsOfRemovedTargets(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	recorder := &reportRecorder{}
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(false, false, false, &labelFormatter{}, recorder, 2)
+	s := newTestScheduler(executor, clock,
+		scheduledTarget("kept", testServer.URL+"/kept", time.Minute),
+		scheduledTarget("removed", testServer.URL+"/removed", time.Minute),
+		scheduledTarget("queued", testServer.URL+"/queued", time.Minute))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	s.work(stop)
+	s.start()
+	for i := 0; i < 2; i++ {
+		dispatch, next := s.next()
+		dispatch <- next
+		s.queue = s.queue[1:]
+	}
+	s.SetTargets([]*config.Target{scheduledTarget("kept", testServer.URL+"/kept", time.Minute)})
+	assert.Empty(t, s.queue)
+
+	changed := make(map[*output.Result]bool)
+	s.finish(<-s.finished, changed)
+	s.finish(<-s.finished, changed)
+	s.report(changed)
+
+	assert.Equal(t, "kept\n", recorder.last())
+}
+
+func TestSchedulerReschedulesEachTargetOnItsOwnResult(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(false, false, false, &labelFormatter{}, &reportRecorder{}, 2)
+	s := newTestScheduler(executor, clock,
+		scheduledTarget("slow", testServer.URL+"/slow", time.Minute),
+		scheduledTarget("fast", testServer.URL+"/fast", 10*time.Second))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	s.work(stop)
+	s.start()
+	for len(s.queue) > 0 {
+		dispatch, next := s.next()
+		dispatch <- next
+		s.queue = s.queue[1:]
+	}
+
+	check := <-s.finished
+	assert.Equal(t, "fast", check.target.Label)
+	s.finish(check, make(map[*output.Result]bool))
+	// the fast target is due again while the slow one is still being checked
+	wait, ok := s.wait()
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, wait)
+
+	check = <-s.finished
+	assert.Equal(t, "slow", check.target.Label)
+}
+
+func TestSchedulerHonorsWorkerCount(t *testing.T) {
+	server := newConcurrencyServer(30 * time.Millisecond)
+	defer server.Close()
+
+	var targets []*config.Target
+	for i, interval := range []time.Duration{10, 15, 20, 25, 30, 35} {
+		targets = append(targets, scheduledTarget(string('a'+rune(i)), server.URL, interval*time.Millisecond))
+	}
+	executor := NewExecutor(false, false, false, &labelFormatter{}, new(bytes.Buffer), 2)
+	s := NewScheduler(executor, targets)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.Run(stop, nil, nil, nil)
+		close(done)
+	}()
+	time.Sleep(500 * time.Millisecond)
+	close(stop)
+	<-done
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	assert.True(t, server.total > len(targets), "targets were not checked again")
+	assert.True(t, server.peak <= 2, "peak concurrency %v exceeds 2 workers", server.peak)
+}
+
+func TestSchedulerCheckDue(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	recorder := &reportRecorder{}
+	clock := &testClock{now: time.Now()}
+	executor := NewExecutor(false, false, false, &labelFormatter{}, recorder, 1)
+	s := newTestScheduler(executor, clock,
+		scheduledTarget("login", testServer.URL+"/login", 10*time.Second),
+		scheduledTarget("report", testServer.URL+"/report", time.Minute))
+
+	s.CheckDue()
+	assert.Equal(t, []string{"login\nreport\n"}, recorder.reports)
+	wait, ok := s.wait()
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, wait)
+
+	s.CheckDue()
+	assert.Len(t, recorder.reports, 1)
+}
+
+func TestSchedulerRun(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	reports := make(chan struct{}, 1)
+	executor := NewExecutor(false, false, false, &labelFormatter{}, new(bytes.Buffer), 1)
+	s := NewScheduler(executor, []*config.Target{scheduledTarget("fast", testServer.URL, 10*time.Millisecond)})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.Run(stop, nil, nil, func() {
+			select {
+			case reports <- struct{}{}:
+			default:
+			}
+		})
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-reports:
+		case <-time.After(5 * time.Second):
+			t.Fatal("target was not checked again")
+		}
+	}
+	close(stop)
+	<-done
+}
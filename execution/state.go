@@ -0,0 +1,198 @@
+package execution
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mkboudreau/asrt/output"
+	"github.com/mkboudreau/asrt/storage"
+)
+
+// StatePolicy decides when a change in a target's results is a change of its state. A target goes down
+// after FailuresToDown failed results in a row and comes back up after SuccessesToUp successful results in a
+// row. It is flapping while its state changed more than FlapChanges times within FlapWindow.
+type StatePolicy struct {
+	FailuresToDown int
+	SuccessesToUp  int
+	FlapChanges    int
+	FlapWindow     time.Duration
+}
+
+// DefaultStatePolicy changes state on every differing result and never reports flapping.
+var DefaultStatePolicy = StatePolicy{FailuresToDown: 1, SuccessesToUp: 1}
+
+func (p StatePolicy) threshold(success bool) int {
+	n := p.FailuresToDown
+	if success {
+		n = p.SuccessesToUp
+	}
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// targetState is what is known about one target between runs
+type targetState struct {
+	reported    stateValue
+	streak      int
+	streakState stateValue
+	changes     []time.Time
+	flapping    bool
+}
+
+type stateTracker struct {
+	policy  StatePolicy
+	targets map[stateKey]*targetState
+	history storage.Store
+	now     func() time.Time
+}
+
+func newStateTracker(policy StatePolicy) *stateTracker {
+	return &stateTracker{policy: policy, targets: make(map[stateKey]*targetState), now: time.Now}
+}
+
+// Observe records a result and reports whether its target changed state, including starting or stopping
+// to flap. Results of flapping targets are marked as such.
+func (tracker *stateTracker) Observe(result *output.Result) bool {
+	k, v := extractStateKeyValueFromResult(result)
+
+	s, ok := tracker.targets[*k]
+	if !ok {
+		s = tracker.stateFromHistory(k)
+		if s == nil {
+			log.Printf("Target %v First State Capture: %v", k, v)
+			tracker.targets[*k] = &targetState{reported: *v}
+			return true
+		}
+		tracker.targets[*k] = s
+	}
+
+	changed := s.observe(*v, tracker.policy, tracker.now())
+	if changed {
+		log.Printf("Target %v Changed to [ %v ]", k, *v)
+	} else {
+		log.Printf("Target %v No Change [ %v ] ", k, s.reported)
+	}
+
+	wasFlapping := s.flapping
+	s.flapping = s.isFlapping(tracker.policy, tracker.now())
+	result.Flapping = s.flapping
+
+	switch {
+	case s.flapping && !wasFlapping:
+		log.Printf("Target %v Started Flapping", k)
+		return true
+	case !s.flapping && wasFlapping:
+		log.Printf("Target %v Stopped Flapping", k)
+		return true
+	case s.flapping:
+		return false
+	}
+	return changed
+}
+
+// forget drops the state of a target, so that its next result starts over as its first
+func (tracker *stateTracker) forget(url string, label string) {
+	delete(tracker.targets, stateKey{Url: url, Label: label})
+}
+
+// stateFromHistory picks up where an earlier run left off, so that a restart is not reported as a change
+func (tracker *stateTracker) stateFromHistory(k *stateKey) *targetState {
+	if tracker.history == nil {
+		return nil
+	}
+
+	r, err := tracker.history.Last(k.Url, k.Label)
+	if err != nil {
+		log.Printf("Could not read history of target %v: %v", k, err)
+		return nil
+	}
+	if r == nil {
+		return nil
+	}
+
+	log.Printf("Target %v State From History: %v", k, r.Time)
+	return &targetState{
+		reported: stateValue{Success: r.Success, Warning: r.Warning, Expected: r.Expected, Actual: r.Actual},
+		flapping: r.Flapping,
+	}
+}
+
+// observe only changes the reported state once enough results in a row agree on a different state. Only
+// success and warning make up the state, so a target that fails in a new way is still the same kind of down.
+func (s *targetState) observe(v stateValue, policy StatePolicy, now time.Time) bool {
+	if v.sameState(s.reported) {
+		s.reported = v
+		s.streak = 0
+		return false
+	}
+
+	if s.streak == 0 || !v.sameState(s.streakState) {
+		s.streak = 0
+		s.streakState = v
+	}
+	s.streak++
+
+	if s.streak < policy.threshold(v.Success) {
+		return false
+	}
+
+	s.reported = v
+	s.streak = 0
+	s.changes = append(s.changes, now)
+	return true
+}
+
+func (s *targetState) isFlapping(policy StatePolicy, now time.Time) bool {
+	if policy.FlapChanges <= 0 {
+		s.changes = nil
+		return false
+	}
+
+	recent := s.changes[:0]
+	for _, t := range s.changes {
+		if now.Sub(t) <= policy.FlapWindow {
+			recent = append(recent, t)
+		}
+	}
+	s.changes = recent
+
+	return len(s.changes) > policy.FlapChanges
+}
+
+type stateKey struct {
+	Url, Label string
+}
+
+func (s stateKey) String() string {
+	return fmt.Sprintf("Label: %v | URL: %v", s.Label, s.Url)
+}
+
+type stateValue struct {
+	Success  bool
+	Warning  bool
+	Expected string
+	Actual   string
+}
+
+// sameState compares whether targets are up, down or warning. Expected and Actual are only there to show.
+func (s stateValue) sameState(other stateValue) bool {
+	return s.Success == other.Success && s.Warning == other.Warning
+}
+
+func (s stateValue) String() string {
+	return fmt.Sprintf("Success: %v | Warning: %v | Expected: %v | Actual: %v", s.Success, s.Warning, s.Expected, s.Actual)
+}
+
+func extractStateKeyValueFromResult(result *output.Result) (*stateKey, *stateValue) {
+	k := &stateKey{Url: result.Url, Label: result.Label}
+	v := &stateValue{
+		Success:  result.Success,
+		Warning:  result.Warning,
+		Expected: result.Expected,
+		Actual:   result.Actual,
+	}
+	return k, v
+}
@@ -0,0 +1,104 @@
+package execution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func stateTestResult(success bool) *output.Result {
+	actual := "200"
+	if !success {
+		actual = "500"
+	}
+	return output.NewResult(success, nil, "200", actual, "http://flaky", "flaky")
+}
+
+// observeAll feeds results to the tracker and returns which of them were reported as changes
+func observeAll(tracker *stateTracker, successes ...bool) []bool {
+	var changes []bool
+	for _, success := range successes {
+		changes = append(changes, tracker.Observe(stateTestResult(success)))
+	}
+	return changes
+}
+
+func TestStateTrackerDefaultPolicy(t *testing.T) {
+	tracker := newStateTracker(DefaultStatePolicy)
+	assert.Equal(t, []bool{true, false, true, true, false}, observeAll(tracker, true, true, false, true, true))
+}
+
+func TestStateTrackerHysteresis(t *testing.T) {
+	tracker := newStateTracker(StatePolicy{FailuresToDown: 3, SuccessesToUp: 2})
+
+	assert.Equal(t, []bool{true}, observeAll(tracker, true))
+	// two failures are not enough and the success in between starts the count again
+	assert.Equal(t, []bool{false, false, false, false, false}, observeAll(tracker, false, false, true, false, false))
+	assert.Equal(t, []bool{true}, observeAll(tracker, false))
+	// down now, so one success is not enough to come back up
+	assert.Equal(t, []bool{false, false, false, true}, observeAll(tracker, true, false, true, true))
+}
+
+func TestStateTrackerHysteresisAcrossFailureKinds(t *testing.T) {
+	tracker := newStateTracker(StatePolicy{FailuresToDown: 2, SuccessesToUp: 1})
+	assert.True(t, tracker.Observe(stateTestResult(true)))
+
+	failed := output.NewResult(false, nil, "200", "503", "http://flaky", "flaky")
+	assert.False(t, tracker.Observe(failed))
+	assert.True(t, tracker.Observe(stateTestResult(false)))
+}
+
+func TestStateTrackerIgnoresChangesWithinAState(t *testing.T) {
+	now := time.Now()
+	tracker := newStateTracker(StatePolicy{FailuresToDown: 2, SuccessesToUp: 1, FlapChanges: 2, FlapWindow: time.Minute})
+	tracker.now = func() time.Time { return now }
+
+	assert.Equal(t, []bool{true, false, true}, observeAll(tracker, true, false, false))
+	for _, actual := range []string{"503", "502", "503", "timeout"} {
+		assert.False(t, tracker.Observe(output.NewResult(false, nil, "200", actual, "http://flaky", "flaky")), actual)
+	}
+
+	result := stateTestResult(true)
+	assert.True(t, tracker.Observe(result))
+	assert.False(t, result.Flapping)
+}
+
+func TestStateTrackerFlapping(t *testing.T) {
+	now := time.Now()
+	tracker := newStateTracker(StatePolicy{FailuresToDown: 1, SuccessesToUp: 1, FlapChanges: 2, FlapWindow: time.Minute})
+	tracker.now = func() time.Time { return now }
+
+	assert.Equal(t, []bool{true, true, true}, observeAll(tracker, true, false, true))
+
+	// the third change within the window starts flapping, reported once
+	result := stateTestResult(false)
+	assert.True(t, tracker.Observe(result))
+	assert.True(t, result.Flapping)
+	assert.Equal(t, "[flap]", result.StatusMessage())
+
+	result = stateTestResult(true)
+	assert.False(t, tracker.Observe(result))
+	assert.True(t, result.Flapping)
+
+	// once the changes age out of the window, settling is reported
+	now = now.Add(2 * time.Minute)
+	result = stateTestResult(true)
+	assert.True(t, tracker.Observe(result))
+	assert.False(t, result.Flapping)
+	assert.Equal(t, []bool{false, true}, observeAll(tracker, true, false))
+}
+
+func TestExecutorForgetTargets(t *testing.T) {
+	executor := NewExecutor(false, false, true, nil, nil, 1)
+	target, _ := config.ParseTarget("www.yahoo.com")
+	result := &output.Result{Success: true, Url: target.URL}
+
+	assert.True(t, executor.state.Observe(result))
+	assert.False(t, executor.state.Observe(result))
+
+	executor.ForgetTargets([]*config.Target{target})
+	assert.True(t, executor.state.Observe(result))
+}
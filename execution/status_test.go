@@ -0,0 +1,46 @@
+package execution
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorStatusExpectations(t *testing.T) {
+	statusToReturn := http.StatusOK
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusToReturn)
+	}))
+	defer testServer.Close()
+
+	var testCases = []struct {
+		expectation string
+		status      int
+		success     bool
+	}{
+		{"200,204", 200, true},
+		{"200,204", 204, true},
+		{"200,204", 201, false},
+		{"2xx", 299, true},
+		{"2xx", 301, false},
+		{"200-399", 302, true},
+		{"200-399", 404, false},
+	}
+
+	for _, tc := range testCases {
+		statusToReturn = tc.status
+		writer := new(bytes.Buffer)
+		formatter := &testResultFormatter{}
+		exec := NewExecutor(false, false, false, formatter, writer, 1)
+		target, err := config.ParseTarget(testServer.URL + "|GET|" + tc.expectation)
+		assert.Nil(t, err)
+
+		exec.Execute([]*config.Target{target})
+		assert.Equal(t, tc.success, formatter.lastResult.Success, "%v with %v", tc.expectation, tc.status)
+		assert.Equal(t, tc.expectation, formatter.lastResult.Expected)
+	}
+}
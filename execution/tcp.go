@@ -0,0 +1,200 @@
+package execution
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+)
+
+// defaultReplyTimeout limits how long tcp and tls targets without a timeout wait for the expected reply
+const defaultReplyTimeout time.Duration = 10 * time.Second
+
+const (
+	tcpConnected   string = "connected"
+	defaultTLSPort        = "443"
+)
+
+func init() {
+	RegisterChecker("tcp", new(tcpChecker))
+	RegisterChecker("tls", &tcpChecker{handshake: true})
+}
+
+// tcpChecker succeeds when it can connect to the host and port of the target, such as tcp://db:5432. For
+// tls targets the TLS handshake has to complete too, and the certificate is reported and checked like that
+// of https targets. A target with Send writes it once connected, and a target with Expect fails unless the
+// reply starts with it. Expect without Send waits for a banner, such as 220 from an SMTP server.
+type tcpChecker struct {
+	handshake bool
+}
+
+func (tc *tcpChecker) Check(target *config.Target) *ExecutionResult {
+	result := &ExecutionResult{URL: target.URL, ExpectedText: tcpConnected}
+	if target.Expect != "" {
+		result.ExpectedText = strconv.Quote(target.Expect)
+	}
+
+	host, port, err := tc.address(target.URL)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	start := time.Now()
+	timings := &output.Timings{}
+	conn, err := dialTCP(target, host, port, start, timings)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	defer conn.Close()
+
+	if tc.handshake {
+		tlsConn, err := handshakeTLS(conn, target, host, start)
+		timings.TLS = output.Duration(time.Since(start) - time.Duration(timings.DNS) - time.Duration(timings.Connect))
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		state := tlsConn.ConnectionState()
+		result.Certificate = newCertificateInfo(&state, time.Now())
+		conn = tlsConn
+	}
+
+	actual, err := exchange(conn, target, start, timings)
+	done := time.Now()
+	result.Duration = done.Sub(start)
+	timings.Total = output.Duration(result.Duration)
+	result.Timings = timings
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.ActualText = actual
+
+	certFailure, certWarning := evaluateCertificateExpiry(target, result.Certificate)
+	if certFailure != "" {
+		result.Assertion = certFailure
+	} else if certWarning != "" {
+		result.Warning = certWarning
+	}
+	return result
+}
+
+// address is the host and port of the target. tls targets default to port 443, tcp targets need a port.
+func (tc *tcpChecker) address(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("%v targets need a host, such as %v://db.internal:5432", u.Scheme, u.Scheme)
+	}
+	port := u.Port()
+	if port == "" && tc.handshake {
+		port = defaultTLSPort
+	}
+	if port == "" {
+		return "", "", fmt.Errorf("%v targets need a port, such as %v://%v:5432", u.Scheme, u.Scheme, u.Hostname())
+	}
+	return u.Hostname(), port, nil
+}
+
+// deadlineFor is the end of the target timeout, which covers every phase, or when the target has none, the
+// end of the timeout of the phase
+func deadlineFor(target *config.Target, start time.Time, phaseTimeout time.Duration) time.Time {
+	if target.Timeout > 0 {
+		return start.Add(target.Timeout)
+	}
+	return time.Now().Add(phaseTimeout)
+}
+
+// dialTCP resolves the host and connects to the first of its addresses that accepts
+func dialTCP(target *config.Target, host string, port string, start time.Time, timings *output.Timings) (net.Conn, error) {
+	ctx, cancel := context.WithDeadline(context.Background(), deadlineFor(target, start, defaultDialTimeout))
+	defer cancel()
+
+	addresses, err := net.DefaultResolver.LookupHost(ctx, host)
+	timings.DNS = output.Duration(time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+
+	connectStart := time.Now()
+	var dialer net.Dialer
+	for _, address := range addresses {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, port))
+		if err == nil {
+			timings.Connect = output.Duration(time.Since(connectStart))
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// handshakeTLS verifies the certificate only when the target asks for strict TLS or gives a CA bundle, like
+// https targets
+func handshakeTLS(conn net.Conn, target *config.Target, host string, start time.Time) (*tls.Conn, error) {
+	tlsConfig, err := tlsConfigForTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig = tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	tlsConn.SetDeadline(deadlineFor(target, start, defaultTLSHandshakeTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// exchange writes the payload of the target and reads as much of the reply as it takes to tell whether it
+// starts with the expected text. The reply is quoted, so that control characters show in the output.
+func exchange(conn net.Conn, target *config.Target, start time.Time, timings *output.Timings) (string, error) {
+	if target.Send == "" && target.Expect == "" {
+		return tcpConnected, nil
+	}
+
+	conn.SetDeadline(deadlineFor(target, start, defaultReplyTimeout))
+	sent := time.Now()
+	if target.Send != "" {
+		if _, err := io.WriteString(conn, target.Send); err != nil {
+			return "", err
+		}
+	}
+	if target.Expect == "" {
+		return tcpConnected, nil
+	}
+
+	var reply []byte
+	buffer := make([]byte, len(target.Expect))
+	for len(reply) < len(target.Expect) && strings.HasPrefix(target.Expect, string(reply)) {
+		n, err := conn.Read(buffer[:len(target.Expect)-len(reply)])
+		if n > 0 && len(reply) == 0 {
+			timings.FirstByte = output.Duration(time.Since(sent))
+		}
+		reply = append(reply, buffer[:n]...)
+		if err == io.EOF || (err != nil && len(reply) > 0) {
+			// the reply ended before it could match, which is reported as a mismatch
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("no reply: %v", err)
+		}
+	}
+	timings.Transfer = output.Duration(time.Since(sent)) - timings.FirstByte
+
+	return strconv.Quote(string(reply)), nil
+}
@@ -0,0 +1,176 @@
+package execution
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// startTCPServer accepts connections and hands each one to handle, until the test server is closed
+func startTCPServer(t *testing.T, handle func(conn net.Conn)) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return listener
+}
+
+// redisLikeServer answers PING with +PONG and anything else with an error
+func redisLikeServer(conn net.Conn) {
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+	if strings.TrimSpace(line) == "PING" {
+		conn.Write([]byte("+PONG\r\n"))
+	} else {
+		conn.Write([]byte("-ERR unknown command\r\n"))
+	}
+}
+
+func tcpTarget(url string, options ...string) *config.Target {
+	target, _ := config.NewTarget("", url, config.MethodGet, 200)
+	for _, option := range options {
+		parts := strings.SplitN(option, "=", 2)
+		target.SetOption(parts[0], parts[1])
+	}
+	return target
+}
+
+func TestTCPConnect(t *testing.T) {
+	listener := startTCPServer(t, func(conn net.Conn) {})
+	defer listener.Close()
+
+	result := ExecuteTarget(tcpTarget("tcp://" + listener.Addr().String()))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.Equal(t, "connected", result.ExpectedString())
+	assert.Equal(t, "connected", result.ActualString())
+	assert.NotNil(t, result.Timings)
+}
+
+func TestTCPConnectRefused(t *testing.T) {
+	listener := startTCPServer(t, func(conn net.Conn) {})
+	address := listener.Addr().String()
+	listener.Close()
+
+	result := ExecuteTarget(tcpTarget("tcp://" + address))
+	assert.False(t, result.Success())
+	assert.NotNil(t, result.Error)
+}
+
+func TestTCPNeedsPort(t *testing.T) {
+	result := ExecuteTarget(tcpTarget("tcp://localhost"))
+	assert.False(t, result.Success())
+	assert.Contains(t, result.Error.Error(), "tcp targets need a port")
+}
+
+func TestTCPSendAndExpect(t *testing.T) {
+	listener := startTCPServer(t, redisLikeServer)
+	defer listener.Close()
+	url := "tcp://" + listener.Addr().String()
+
+	result := ExecuteTarget(tcpTarget(url, `send=PING\r\n`, "expect=+PONG"))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.Equal(t, `"+PONG"`, result.ActualString())
+
+	result = ExecuteTarget(tcpTarget(url, `send=HELLO\r\n`, "expect=+PONG"))
+	assert.Nil(t, result.Error)
+	assert.False(t, result.Success())
+	assert.Equal(t, `"+PONG"`, result.ExpectedString())
+	assert.Equal(t, `"-ERR "`, result.ActualString())
+}
+
+func TestTCPExpectBanner(t *testing.T) {
+	listener := startTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("220 mail.example.com ESMTP\r\n"))
+	})
+	defer listener.Close()
+
+	result := ExecuteTarget(tcpTarget("tcp://"+listener.Addr().String(), "expect=220 "))
+	assert.True(t, result.Success())
+
+	result = ExecuteTarget(tcpTarget("tcp://"+listener.Addr().String(), "expect=220 mail.example.com ESMTP\r\n250"))
+	assert.False(t, result.Success())
+	assert.Nil(t, result.Error)
+}
+
+func TestTCPNoReply(t *testing.T) {
+	listener := startTCPServer(t, func(conn net.Conn) {
+		time.Sleep(time.Second)
+	})
+	defer listener.Close()
+
+	target := tcpTarget("tcp://"+listener.Addr().String(), `send=PING\r\n`, "expect=+PONG")
+	target.Timeout = 100 * time.Millisecond
+	result := ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.Contains(t, result.Error.Error(), "no reply")
+}
+
+func TestTLSHandshake(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer testServer.Close()
+	address := strings.TrimPrefix(testServer.URL, "https://")
+
+	result := ExecuteTarget(tcpTarget("tls://" + address))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.NotNil(t, result.Certificate)
+	assert.Contains(t, result.Certificate.SANs, "example.com")
+	assert.True(t, result.Timings.TLS > 0)
+
+	target := tcpTarget("tls://"+address, "strict-tls=true")
+	result = ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.NotNil(t, result.Error)
+}
+
+func TestTLSHandshakeWithPlainServer(t *testing.T) {
+	listener := startTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("not tls\r\n"))
+	})
+	defer listener.Close()
+
+	target := tcpTarget("tls://" + listener.Addr().String())
+	target.Timeout = time.Second
+	result := ExecuteTarget(target)
+	assert.False(t, result.Success())
+	assert.NotNil(t, result.Error)
+}
+
+func TestTCPThroughExecutor(t *testing.T) {
+	listener := startTCPServer(t, redisLikeServer)
+	defer listener.Close()
+
+	formatter := &testResultFormatter{}
+	exec := NewExecutor(false, false, false, formatter, new(bytes.Buffer), 1)
+	target := tcpTarget("tcp://"+listener.Addr().String(), `send=PING\r\n`, "expect=+PONG")
+	target.Label = "redis"
+
+	assert.Equal(t, 0, exec.Execute([]*config.Target{target}))
+	assert.Equal(t, `"+PONG"`, formatter.lastResult.Expected)
+	assert.Equal(t, `"+PONG"`, formatter.lastResult.Actual)
+	assert.Equal(t, "redis", formatter.lastResult.Label)
+}
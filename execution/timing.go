@@ -0,0 +1,99 @@
+package execution
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/mkboudreau/asrt/output"
+)
+
+// timingTrace collects the phases of a request through net/http/httptrace.
+// When redirects are followed, the dns, connect and tls phases add up over every hop.
+type timingTrace struct {
+	mutex        sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	dns          time.Duration
+	connect      time.Duration
+	tls          time.Duration
+}
+
+func newTimingTrace(start time.Time) *timingTrace {
+	return &timingTrace{start: start}
+}
+
+func (tt *timingTrace) ClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			tt.mutex.Lock()
+			tt.dnsStart = time.Now()
+			tt.mutex.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			tt.mutex.Lock()
+			tt.dns += time.Since(tt.dnsStart)
+			tt.mutex.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			tt.mutex.Lock()
+			if tt.connectStart.IsZero() {
+				tt.connectStart = time.Now()
+			}
+			tt.mutex.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			tt.mutex.Lock()
+			if err == nil && !tt.connectStart.IsZero() {
+				tt.connect += time.Since(tt.connectStart)
+				tt.connectStart = time.Time{}
+			}
+			tt.mutex.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			tt.mutex.Lock()
+			tt.tlsStart = time.Now()
+			tt.mutex.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tt.mutex.Lock()
+			tt.tls += time.Since(tt.tlsStart)
+			tt.mutex.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			tt.mutex.Lock()
+			tt.wroteRequest = time.Now()
+			tt.mutex.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			tt.mutex.Lock()
+			tt.firstByte = time.Now()
+			tt.mutex.Unlock()
+		},
+	}
+}
+
+// Timings returns the breakdown for a request whose body finished transferring at done.
+func (tt *timingTrace) Timings(done time.Time) *output.Timings {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+
+	timings := &output.Timings{
+		DNS:     output.Duration(tt.dns),
+		Connect: output.Duration(tt.connect),
+		TLS:     output.Duration(tt.tls),
+		Total:   output.Duration(done.Sub(tt.start)),
+	}
+	if !tt.firstByte.IsZero() {
+		if !tt.wroteRequest.IsZero() {
+			timings.FirstByte = output.Duration(tt.firstByte.Sub(tt.wroteRequest))
+		}
+		timings.Transfer = output.Duration(done.Sub(tt.firstByte))
+	}
+	return timings
+}
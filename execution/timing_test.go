@@ -0,0 +1,44 @@
+package execution
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteTargetTimings(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("done"))
+	}))
+	defer testServer.Close()
+
+	target, _ := config.NewTarget("abc", testServer.URL, config.MethodGet, 200)
+	result := ExecuteTarget(target)
+
+	assert.True(t, result.Success())
+	assert.NotNil(t, result.Timings)
+	// how the sleeps of the server split between the phases depends on scheduling, so only their sum is certain
+	timings := result.Timings
+	for _, phase := range []output.Duration{timings.Connect, timings.TLS, timings.FirstByte, timings.Transfer} {
+		assert.True(t, phase > 0)
+	}
+	assert.True(t, timings.DNS+timings.Connect+timings.TLS+timings.FirstByte+timings.Transfer <= timings.Total)
+	assert.Equal(t, result.Duration, time.Duration(timings.Total))
+}
+
+func TestExecuteTargetTimingsOnError(t *testing.T) {
+	target, _ := config.NewTarget("abc", "http://127.0.0.1:1", config.MethodGet, 200)
+	result := ExecuteTarget(target)
+
+	assert.NotNil(t, result.Error)
+	assert.Nil(t, result.Timings)
+}
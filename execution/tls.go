@@ -0,0 +1,90 @@
+package execution
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/mkboudreau/asrt/config"
+)
+
+// tlsSettings are the target settings that determine its tls.Config
+type tlsSettings struct {
+	Strict     bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+func newTLSSettings(target *config.Target) tlsSettings {
+	return tlsSettings{
+		Strict:     target.StrictTLS,
+		CAFile:     target.TLSCAFile,
+		CertFile:   target.TLSCertFile,
+		KeyFile:    target.TLSKeyFile,
+		ServerName: target.TLSServerName,
+	}
+}
+
+var (
+	tlsConfigs      = make(map[tlsSettings]*tls.Config)
+	tlsConfigsMutex = &sync.Mutex{}
+)
+
+// tlsConfigForTarget loads CA bundles and client certificates once per distinct combination of settings,
+// until ResetTransports.
+func tlsConfigForTarget(target *config.Target) (*tls.Config, error) {
+	return tlsConfigForSettings(newTLSSettings(target))
+}
+
+func tlsConfigForSettings(settings tlsSettings) (*tls.Config, error) {
+	tlsConfigsMutex.Lock()
+	defer tlsConfigsMutex.Unlock()
+
+	if c, ok := tlsConfigs[settings]; ok {
+		return c, nil
+	}
+
+	c, err := settings.load()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfigs[settings] = c
+	return c, nil
+}
+
+// load verifies certificates when strict TLS is on or a CA bundle is given, since the bundle would otherwise be ignored.
+func (s tlsSettings) load() (*tls.Config, error) {
+	c := &tls.Config{
+		InsecureSkipVerify: !s.Strict && s.CAFile == "",
+		ServerName:         s.ServerName,
+	}
+
+	if s.CAFile != "" {
+		pem, err := ioutil.ReadFile(s.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read ca file %v: %v", s.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca file %v", s.CAFile)
+		}
+		c.RootCAs = pool
+	}
+
+	if s.CertFile != "" || s.KeyFile != "" {
+		if s.CertFile == "" || s.KeyFile == "" {
+			return nil, fmt.Errorf("client certificates need both a cert file and a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate %v: %v", s.CertFile, err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+
+	return c, nil
+}
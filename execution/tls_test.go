@@ -0,0 +1,141 @@
+package execution
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteTargetWithCAFileAndClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asrt-tls")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	clientCert, certFile, keyFile := writeTestClientCertificate(t, dir)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	testServer.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", testServer.Certificate().Raw)
+
+	var testCases = []struct {
+		caFile, certFile, keyFile, serverName string
+		success                               bool
+	}{
+		{"", "", "", "", false},
+		{caFile, "", "", "", false},
+		{caFile, certFile, keyFile, "", true},
+		{caFile, certFile, keyFile, "example.com", true},
+		{caFile, certFile, keyFile, "wrong.example.org", false},
+		{"", certFile, keyFile, "", true},
+		{filepath.Join(dir, "missing.pem"), certFile, keyFile, "", false},
+		{caFile, certFile, "", "", false},
+	}
+
+	for i, tc := range testCases {
+		target, _ := config.NewTarget("abc", testServer.URL, config.MethodGet, 200)
+		target.TLSCAFile = tc.caFile
+		target.TLSCertFile = tc.certFile
+		target.TLSKeyFile = tc.keyFile
+		target.TLSServerName = tc.serverName
+
+		result := ExecuteTarget(target)
+		assert.Equal(t, tc.success, result.Success(), "test case %v: %v", i, result.Error)
+	}
+}
+
+func TestTLSConfigIsReused(t *testing.T) {
+	target := &config.Target{StrictTLS: true, TLSServerName: "reused.example.com"}
+	first, err := tlsConfigForTarget(target)
+	assert.Nil(t, err)
+	second, err := tlsConfigForTarget(&config.Target{StrictTLS: true, TLSServerName: "reused.example.com"})
+	assert.Nil(t, err)
+	assert.True(t, first == second)
+
+	other, err := tlsConfigForTarget(&config.Target{TLSServerName: "reused.example.com"})
+	assert.Nil(t, err)
+	assert.False(t, first == other)
+	assert.True(t, other.InsecureSkipVerify)
+}
+
+func TestResetTransportsReadsRotatedCertificates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asrt-tls")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	first, certFile, keyFile := writeTestClientCertificate(t, dir)
+	target := &config.Target{TLSCertFile: certFile, TLSKeyFile: keyFile}
+	tlsConfig, err := tlsConfigForTarget(target)
+	assert.Nil(t, err)
+	tr, err := transportForTarget(target)
+	assert.Nil(t, err)
+
+	rotated, _, _ := writeTestClientCertificate(t, dir)
+	cached, err := tlsConfigForTarget(target)
+	assert.Nil(t, err)
+	assert.Equal(t, first.Raw, cached.Certificates[0].Certificate[0])
+
+	ResetTransports()
+	reloaded, err := tlsConfigForTarget(target)
+	assert.Nil(t, err)
+	assert.False(t, tlsConfig == reloaded)
+	assert.Equal(t, rotated.Raw, reloaded.Certificates[0].Certificate[0])
+	reloadedTransport, err := transportForTarget(target)
+	assert.Nil(t, err)
+	assert.False(t, tr == reloadedTransport)
+}
+
+func writeTestClientCertificate(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "asrt test client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDer)
+	return cert, certFile, keyFile
+}
+
+func writePEM(t *testing.T, filename string, blockType string, der []byte) {
+	b := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	assert.Nil(t, ioutil.WriteFile(filename, b, 0600))
+}
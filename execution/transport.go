@@ -0,0 +1,193 @@
+package execution
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"golang.org/x/net/http2"
+)
+
+const (
+	defaultDialTimeout         time.Duration = 30 * time.Second
+	defaultTLSHandshakeTimeout               = 10 * time.Second
+	idleConnectionTimeout                    = 90 * time.Second
+)
+
+// transportSettings are the target settings that need a transport of their own
+type transportSettings struct {
+	TLS     tlsSettings
+	Proxy   string
+	Timeout time.Duration
+	// HTTP2 speaks HTTP/2 over TLS, the way grpcs needs it. It has transports of its own, since HTTP/2
+	// shares connections by address regardless of scheme.
+	HTTP2 bool
+}
+
+func newTransportSettings(target *config.Target) transportSettings {
+	return transportSettings{
+		TLS:     newTLSSettings(target),
+		Proxy:   target.Proxy,
+		Timeout: target.Timeout,
+	}
+}
+
+var (
+	transports      = make(map[transportSettings]*http.Transport)
+	h2cTransports   = make(map[transportSettings]*http2.Transport)
+	transportsMutex = &sync.Mutex{}
+)
+
+// transportForTarget shares one long-lived transport, and so its idle connections, between every target
+// with the same settings. Targets with a fresh connection get a transport of their own that does not keep
+// connections alive, so that every check pays for dns, connect and tls again.
+func transportForTarget(target *config.Target) (*http.Transport, error) {
+	return transportForSettings(newTransportSettings(target), target.FreshConnection)
+}
+
+func transportForSettings(settings transportSettings, fresh bool) (*http.Transport, error) {
+	if fresh {
+		tr, err := settings.load()
+		if err != nil {
+			return nil, err
+		}
+		tr.DisableKeepAlives = true
+		return tr, nil
+	}
+
+	transportsMutex.Lock()
+	defer transportsMutex.Unlock()
+
+	if tr, ok := transports[settings]; ok {
+		return tr, nil
+	}
+
+	tr, err := settings.load()
+	if err != nil {
+		return nil, err
+	}
+	transports[settings] = tr
+	return tr, nil
+}
+
+// h2cTransportForSettings is transportForSettings for HTTP/2 in the clear, the way grpc needs it, which
+// net/http does not speak on its own. Fresh transports are not shared, and their connection is left to the
+// caller to close.
+func h2cTransportForSettings(settings transportSettings, fresh bool) *http2.Transport {
+	if fresh {
+		return settings.loadH2C()
+	}
+
+	transportsMutex.Lock()
+	defer transportsMutex.Unlock()
+
+	if tr, ok := h2cTransports[settings]; ok {
+		return tr
+	}
+	tr := settings.loadH2C()
+	h2cTransports[settings] = tr
+	return tr
+}
+
+// ResetTransports drops every shared transport and tls configuration, so that the next checks read CA
+// bundles and client certificates again, such as after they were rotated.
+func ResetTransports() {
+	transportsMutex.Lock()
+	defer transportsMutex.Unlock()
+
+	for _, tr := range transports {
+		tr.CloseIdleConnections()
+	}
+	for _, tr := range h2cTransports {
+		tr.CloseIdleConnections()
+	}
+	transports = make(map[transportSettings]*http.Transport)
+	h2cTransports = make(map[transportSettings]*http2.Transport)
+
+	tlsConfigsMutex.Lock()
+	defer tlsConfigsMutex.Unlock()
+	tlsConfigs = make(map[tlsSettings]*tls.Config)
+}
+
+// CloseIdleConnections closes the idle connections kept by every shared transport.
+func CloseIdleConnections() {
+	transportsMutex.Lock()
+	defer transportsMutex.Unlock()
+
+	for _, tr := range transports {
+		tr.CloseIdleConnections()
+	}
+	for _, tr := range h2cTransports {
+		tr.CloseIdleConnections()
+	}
+}
+
+// load uses the proxy from the environment unless the target names one. A target timeout shorter than the
+// default dial and handshake timeouts shortens those too. HTTP/2 transports only use a proxy that the
+// target names.
+func (s transportSettings) load() (*http.Transport, error) {
+	tlsConfig, err := tlsConfigForSettings(s.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if s.Proxy != "" {
+		proxyURL, err := url.Parse(s.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %v: %v", s.Proxy, err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	if s.HTTP2 && s.Proxy == "" {
+		proxy = nil
+	}
+
+	tr := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   shorterTimeout(defaultDialTimeout, s.Timeout),
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout: shorterTimeout(defaultTLSHandshakeTimeout, s.Timeout),
+		TLSClientConfig:     tlsConfig.Clone(), // HTTP/2 adds its protocol to the shared config otherwise
+		IdleConnTimeout:     idleConnectionTimeout,
+	}
+	if s.HTTP2 {
+		tr.ForceAttemptHTTP2 = true
+		if err := http2.ConfigureTransport(tr); err != nil {
+			return nil, err
+		}
+	}
+	return tr, nil
+}
+
+// loadH2C dials plain tcp where HTTP/2 would dial tls. It does not use a proxy, since plain HTTP proxies
+// cannot forward HTTP/2 in the clear.
+func (s transportSettings) loadH2C() *http2.Transport {
+	dialer := &net.Dialer{
+		Timeout:   shorterTimeout(defaultDialTimeout, s.Timeout),
+		KeepAlive: 30 * time.Second,
+	}
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network string, address string, _ *tls.Config) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+		IdleConnTimeout: idleConnectionTimeout,
+	}
+}
+
+func shorterTimeout(timeout time.Duration, targetTimeout time.Duration) time.Duration {
+	if targetTimeout > 0 && targetTimeout < timeout {
+		return targetTimeout
+	}
+	return timeout
+}
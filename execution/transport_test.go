@@ -0,0 +1,117 @@
+package execution
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// newConnectionCountingServer counts the tcp connections clients open to it
+func newConnectionCountingServer() (*httptest.Server, func() int) {
+	var mutex sync.Mutex
+	connections := 0
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mutex.Lock()
+			connections++
+			mutex.Unlock()
+		}
+	}
+	server.Start()
+
+	return server, func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return connections
+	}
+}
+
+func TestTransportIsShared(t *testing.T) {
+	first, err := transportForTarget(&config.Target{Timeout: time.Second, TLSServerName: "shared.example.com"})
+	assert.Nil(t, err)
+	second, err := transportForTarget(&config.Target{Timeout: time.Second, TLSServerName: "shared.example.com", Label: "other"})
+	assert.Nil(t, err)
+	assert.True(t, first == second)
+
+	other, err := transportForTarget(&config.Target{Timeout: 2 * time.Second, TLSServerName: "shared.example.com"})
+	assert.Nil(t, err)
+	assert.False(t, first == other)
+
+	proxied, err := transportForTarget(&config.Target{Timeout: time.Second, TLSServerName: "shared.example.com", Proxy: "http://proxy.example.com:3128"})
+	assert.Nil(t, err)
+	assert.False(t, first == proxied)
+
+	fresh, err := transportForTarget(&config.Target{Timeout: time.Second, TLSServerName: "shared.example.com", FreshConnection: true})
+	assert.Nil(t, err)
+	assert.False(t, first == fresh)
+	assert.True(t, fresh.DisableKeepAlives)
+}
+
+func TestHTTP2TransportsLeaveTLSConfigAlone(t *testing.T) {
+	target := &config.Target{Timeout: time.Second, TLSServerName: "h2.example.com"}
+	settings := newTransportSettings(target)
+	settings.HTTP2 = true
+	tr, err := transportForSettings(settings, false)
+	assert.Nil(t, err)
+	shared, err := transportForTarget(target)
+	assert.Nil(t, err)
+	assert.False(t, tr == shared)
+
+	server, _ := startHealthServer(testHealthStatuses, true)
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := tr.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, resp.ProtoMajor)
+	resp.Body.Close()
+
+	tlsConfig, err := tlsConfigForTarget(target)
+	assert.Nil(t, err)
+	assert.Empty(t, tlsConfig.NextProtos)
+}
+
+func TestTransportTimeouts(t *testing.T) {
+	tr, err := transportForTarget(&config.Target{Timeout: 2 * time.Second})
+	assert.Nil(t, err)
+	assert.Equal(t, 2*time.Second, tr.TLSHandshakeTimeout)
+
+	tr, err = transportForTarget(&config.Target{Timeout: time.Minute})
+	assert.Nil(t, err)
+	assert.Equal(t, defaultTLSHandshakeTimeout, tr.TLSHandshakeTimeout)
+}
+
+func TestConnectionsAreReused(t *testing.T) {
+	server, connections := newConnectionCountingServer()
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "|GET|200")
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		assert.True(t, ExecuteTarget(target).Success())
+	}
+	assert.Equal(t, 1, connections())
+}
+
+func TestFreshConnection(t *testing.T) {
+	server, connections := newConnectionCountingServer()
+	defer server.Close()
+
+	target, err := config.ParseTarget(server.URL + "|GET|200|{O}fresh-connection")
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		result := ExecuteTarget(target)
+		assert.True(t, result.Success())
+		assert.True(t, result.Timings.Connect > 0)
+	}
+	assert.Equal(t, 3, connections())
+}
@@ -0,0 +1,401 @@
+package execution
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+)
+
+const (
+	wsUpgraded    string = "upgraded"
+	wsAcceptGUID         = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	defaultWSPort        = "80"
+)
+
+// opcodes of websocket frames
+const (
+	wsText  byte = 0x1
+	wsClose      = 0x8
+	wsPing       = 0x9
+	wsPong       = 0xa
+)
+
+func init() {
+	RegisterChecker("ws", new(wsChecker))
+	RegisterChecker("wss", &wsChecker{secure: true})
+}
+
+// wsChecker succeeds when the server upgrades the connection to a websocket, such as
+// ws://realtime.internal/socket. wss targets upgrade over TLS and report the certificate like https targets.
+// Headers are sent with the upgrade request, through a CONNECT tunnel when there is a proxy. A target with
+// Send sends it as a text message once upgraded, and a target with Expect waits for a message that starts
+// with it, skipping the messages before it until the timeout. The upgrade is reported as the time to first
+// byte, and the wait for the reply as the transfer.
+type wsChecker struct {
+	secure bool
+}
+
+func (wc *wsChecker) Check(target *config.Target) *ExecutionResult {
+	result := &ExecutionResult{URL: target.URL, ExpectedText: wsUpgraded}
+	if target.Expect != "" {
+		result.ExpectedText = strconv.Quote(target.Expect)
+	}
+
+	u, port, err := wc.address(target.URL)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	start := time.Now()
+	timings := &output.Timings{}
+	conn, err := wc.dial(target, u, port, start, timings)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	defer conn.Close()
+
+	if wc.secure {
+		tlsConn, err := handshakeTLS(conn, target, u.Hostname(), start)
+		timings.TLS = output.Duration(time.Since(start) - time.Duration(timings.DNS) - time.Duration(timings.Connect))
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		state := tlsConn.ConnectionState()
+		result.Certificate = newCertificateInfo(&state, time.Now())
+		conn = tlsConn
+	}
+
+	conn.SetDeadline(deadlineFor(target, start, defaultReplyTimeout))
+	reader := bufio.NewReader(conn)
+	upgradeStart := time.Now()
+	status, err := upgradeWebSocket(conn, reader, u, target.Headers)
+	timings.FirstByte = output.Duration(time.Since(upgradeStart))
+	if err == nil && status == wsUpgraded {
+		sent := time.Now()
+		status, err = exchangeWebSocket(conn, reader, target)
+		if target.Send != "" || target.Expect != "" {
+			timings.Transfer = output.Duration(time.Since(sent))
+		}
+		closeWebSocket(conn)
+	}
+	result.Duration = time.Since(start)
+	timings.Total = output.Duration(result.Duration)
+	result.Timings = timings
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.ActualText = status
+
+	certFailure, certWarning := evaluateCertificateExpiry(target, result.Certificate)
+	if certFailure != "" {
+		result.Assertion = certFailure
+	} else if certWarning != "" {
+		result.Warning = certWarning
+	}
+	return result
+}
+
+// address is the URL and port of the target. ws targets default to port 80 and wss targets to port 443.
+func (wc *wsChecker) address(rawURL string) (*url.URL, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if u.Hostname() == "" {
+		return nil, "", fmt.Errorf("%v targets need a host, such as %v://realtime.internal/socket", u.Scheme, u.Scheme)
+	}
+	port := u.Port()
+	if port == "" && wc.secure {
+		port = defaultTLSPort
+	} else if port == "" {
+		port = defaultWSPort
+	}
+	return u, port, nil
+}
+
+// dial connects to the host of the target, through a CONNECT tunnel when the target has a proxy or the
+// environment names one, like https targets. The tunnel is reported as part of the connect time.
+func (wc *wsChecker) dial(target *config.Target, u *url.URL, port string, start time.Time, timings *output.Timings) (net.Conn, error) {
+	proxyURL, err := wc.proxy(target, u, port)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return dialTCP(target, u.Hostname(), port, start, timings)
+	}
+	if proxyURL.Scheme != "http" {
+		return nil, fmt.Errorf("websocket targets can only tunnel through http proxies, not %v", proxyURL)
+	}
+
+	proxyPort := proxyURL.Port()
+	if proxyPort == "" {
+		proxyPort = defaultWSPort
+	}
+	conn, err := dialTCP(target, proxyURL.Hostname(), proxyPort, start, timings)
+	if err != nil {
+		return nil, err
+	}
+	tunnelStart := time.Now()
+	conn.SetDeadline(deadlineFor(target, start, defaultDialTimeout))
+	if err := connectTunnel(conn, proxyURL, net.JoinHostPort(u.Hostname(), port)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	timings.Connect += output.Duration(time.Since(tunnelStart))
+	return conn, nil
+}
+
+// proxy is the proxy of the target, or else the one the environment names for http, or https for wss, targets
+func (wc *wsChecker) proxy(target *config.Target, u *url.URL, port string) (*url.URL, error) {
+	if target.Proxy != "" {
+		proxyURL, err := url.Parse(target.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %v: %v", target.Proxy, err)
+		}
+		return proxyURL, nil
+	}
+
+	scheme := "http"
+	if wc.secure {
+		scheme = "https"
+	}
+	return http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: scheme, Host: net.JoinHostPort(u.Hostname(), port)}})
+}
+
+// connectTunnel asks the proxy to connect to address, authenticating with the user of the proxy url if any
+func connectTunnel(conn net.Conn, proxyURL *url.URL, address string) error {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+
+	// nothing comes through the tunnel before the client speaks, so the reader cannot take more than the answer
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy %v did not open a tunnel to %v: %v", proxyURL.Host, address, resp.Status)
+	}
+	return nil
+}
+
+// upgradeWebSocket sends the upgrade request and returns wsUpgraded when the server switched protocols, or the
+// status it answered with instead, such as 426 Upgrade Required
+func upgradeWebSocket(conn net.Conn, reader *bufio.Reader, u *url.URL, headers map[string]string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: u.EscapedPath(), RawQuery: u.RawQuery},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Host:       u.Host,
+		Header:     http.Header{},
+	}
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+	for name, value := range headers {
+		req.Header.Add(name, value)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		return "", err
+	}
+
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return resp.Status, nil
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return "", fmt.Errorf("server switched to %q rather than websocket", resp.Header.Get("Upgrade"))
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		return "", errors.New("server did not accept the websocket key")
+	}
+	return wsUpgraded, nil
+}
+
+// webSocketAccept is the answer a server has to give to the key of an upgrade request
+func webSocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// exchangeWebSocket sends the payload of the target and waits for a message that starts with the expected
+// text. When none does before the deadline or the server closes, it returns the last message, quoted.
+func exchangeWebSocket(conn net.Conn, reader *bufio.Reader, target *config.Target) (string, error) {
+	if target.Send != "" {
+		if err := writeWebSocketFrame(conn, wsText, []byte(target.Send)); err != nil {
+			return "", err
+		}
+	}
+	if target.Expect == "" {
+		return wsUpgraded, nil
+	}
+
+	var last []byte
+	received := false
+	for {
+		message, err := readWebSocketMessage(conn, reader)
+		if err != nil && !received {
+			return "", fmt.Errorf("no reply: %v", err)
+		} else if err != nil {
+			return strconv.Quote(string(last)), nil
+		}
+		if strings.HasPrefix(string(message), target.Expect) {
+			// like tcp targets, a match is reported as the part that was expected
+			return strconv.Quote(target.Expect), nil
+		}
+		last, received = message, true
+	}
+}
+
+var errWebSocketClosed = errors.New("websocket closed by the server")
+
+// readWebSocketMessage reads the frames of the next text or binary message, answering pings on the way
+func readWebSocketMessage(conn net.Conn, reader *bufio.Reader) ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := readWebSocketFrame(reader)
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := writeWebSocketFrame(conn, wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			return nil, errWebSocketClosed
+		}
+
+		message = append(message, payload...)
+		if int64(len(message)) > MaxBodySize {
+			return nil, fmt.Errorf("websocket message larger than %d bytes", MaxBodySize)
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func readWebSocketFrame(reader *bufio.Reader) (bool, byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, opcode, masked := header[0]&0x80 != 0, header[0]&0x0f, header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var extended uint16
+		if err := binary.Read(reader, binary.BigEndian, &extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(extended)
+	case 127:
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	if length > uint64(MaxBodySize) {
+		return false, 0, nil, fmt.Errorf("websocket message larger than %d bytes", MaxBodySize)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeWebSocketFrame writes a single, final frame. Frames from clients are always masked.
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 0x80|126, byte(length>>8), byte(length))
+	default:
+		frame = append(frame, 0x80|127)
+		extended := make([]byte, 8)
+		binary.BigEndian.PutUint64(extended, uint64(length))
+		frame = append(frame, extended...)
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// closeWebSocket tells the server the check is done, without waiting for it to agree
+func closeWebSocket(conn net.Conn) {
+	writeWebSocketFrame(conn, wsClose, []byte{0x03, 0xe8}) // 1000, normal closure
+}
@@ -0,0 +1,239 @@
+package execution
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// webSocketServer upgrades requests with a token and hands the connection to handle, until the test
+// server is closed
+func webSocketServer(secure bool, handle func(conn net.Conn, reader *bufio.Reader)) *httptest.Server {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			w.WriteHeader(http.StatusUpgradeRequired)
+			return
+		}
+		conn, buffered, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buffered.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		buffered.WriteString("Sec-WebSocket-Accept: " + webSocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		buffered.Flush()
+		handle(conn, buffered.Reader)
+	})
+	if secure {
+		return httptest.NewTLSServer(handler)
+	}
+	return httptest.NewServer(handler)
+}
+
+// writeServerFrame writes an unmasked frame, as servers do
+func writeServerFrame(conn net.Conn, fin bool, opcode byte, payload string) {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	conn.Write(append([]byte{first, byte(len(payload))}, payload...))
+}
+
+// echoServer answers every message with the message, prefixed with echo
+func echoServer(conn net.Conn, reader *bufio.Reader) {
+	for {
+		_, opcode, payload, err := readWebSocketFrame(reader)
+		if err != nil || opcode == wsClose {
+			return
+		}
+		writeServerFrame(conn, true, wsText, "echo: "+string(payload))
+	}
+}
+
+func wsTarget(server *httptest.Server, scheme string, options ...string) *config.Target {
+	address := server.Listener.Addr().String()
+	target := tcpTarget(scheme+"://"+address+"/socket?room=1", options...)
+	target.Headers = map[string]string{"Authorization": "Bearer token"}
+	target.Timeout = time.Second
+	return target
+}
+
+func TestWebSocketUpgrade(t *testing.T) {
+	server := webSocketServer(false, echoServer)
+	defer server.Close()
+
+	result := ExecuteTarget(wsTarget(server, "ws"))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.Equal(t, "upgraded", result.ActualString())
+	assert.True(t, result.Timings.FirstByte > 0)
+	assert.True(t, result.Timings.Transfer == 0)
+
+	target := wsTarget(server, "ws")
+	target.Headers = nil
+	result = ExecuteTarget(target)
+	assert.Nil(t, result.Error)
+	assert.False(t, result.Success())
+	assert.Equal(t, "401 Unauthorized", result.ActualString())
+	assert.Equal(t, "upgraded", result.ExpectedString())
+}
+
+func TestWebSocketAgainstHTTPServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUpgradeRequired)
+	}))
+	defer server.Close()
+
+	result := ExecuteTarget(wsTarget(server, "ws"))
+	assert.False(t, result.Success())
+	assert.Equal(t, "426 Upgrade Required", result.ActualString())
+}
+
+func TestWebSocketSendAndExpect(t *testing.T) {
+	server := webSocketServer(false, echoServer)
+	defer server.Close()
+
+	result := ExecuteTarget(wsTarget(server, "ws", "send=ping", "expect=echo: ping"))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.Equal(t, `"echo: ping"`, result.ActualString())
+	assert.True(t, result.Timings.Transfer > 0)
+
+	result = ExecuteTarget(wsTarget(server, "ws", "send=ping", "expect=pong"))
+	assert.Nil(t, result.Error)
+	assert.False(t, result.Success())
+	assert.Equal(t, `"pong"`, result.ExpectedString())
+	assert.Equal(t, `"echo: ping"`, result.ActualString())
+}
+
+func TestWebSocketSkipsMessagesUntilExpected(t *testing.T) {
+	server := webSocketServer(false, func(conn net.Conn, reader *bufio.Reader) {
+		writeServerFrame(conn, true, wsText, "welcome")
+		writeServerFrame(conn, true, wsPing, "are you there")
+		if _, opcode, payload, err := readWebSocketFrame(reader); err != nil || opcode != wsPong || string(payload) != "are you there" {
+			return
+		}
+		writeServerFrame(conn, false, wsText, "ready")
+		writeServerFrame(conn, true, 0x0, " to go")
+		readWebSocketFrame(reader)
+	})
+	defer server.Close()
+
+	result := ExecuteTarget(wsTarget(server, "ws", "expect=ready to go"))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.Equal(t, `"ready to go"`, result.ActualString())
+}
+
+func TestWebSocketClosedWithoutReply(t *testing.T) {
+	server := webSocketServer(false, func(conn net.Conn, reader *bufio.Reader) {
+		writeServerFrame(conn, true, wsClose, "\x03\xe8")
+	})
+	defer server.Close()
+
+	result := ExecuteTarget(wsTarget(server, "ws", "expect=hello"))
+	assert.False(t, result.Success())
+	assert.EqualError(t, result.Error, "no reply: websocket closed by the server")
+}
+
+func TestWebSocketWrongAccept(t *testing.T) {
+	listener := startTCPServer(t, func(conn net.Conn) {
+		bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: nope\r\n\r\n"))
+	})
+	defer listener.Close()
+
+	result := ExecuteTarget(tcpTarget("ws://" + listener.Addr().String()))
+	assert.False(t, result.Success())
+	assert.NotNil(t, result.Error)
+}
+
+func TestSecureWebSocket(t *testing.T) {
+	server := webSocketServer(true, echoServer)
+	defer server.Close()
+
+	result := ExecuteTarget(wsTarget(server, "wss", "send=ping", "expect=echo: ping"))
+	assert.Nil(t, result.Error)
+	assert.True(t, result.Success())
+	assert.NotNil(t, result.Certificate)
+	assert.True(t, result.Timings.TLS > 0)
+}
+
+func TestWebSocketFrameLengths(t *testing.T) {
+	for _, length := range []int{0, 125, 126, 65535, 65536} {
+		var buffer bytes.Buffer
+		payload := strings.Repeat("x", length)
+		assert.Nil(t, writeWebSocketFrame(&buffer, wsText, []byte(payload)))
+
+		fin, opcode, read, err := readWebSocketFrame(bufio.NewReader(&buffer))
+		assert.Nil(t, err, "%d", length)
+		assert.True(t, fin, "%d", length)
+		assert.Equal(t, wsText, opcode, "%d", length)
+		assert.Equal(t, payload, string(read), "%d", length)
+	}
+}
+
+// connectProxy tunnels CONNECT requests to their address, and refuses them without the proxy credentials
+func connectProxy(t *testing.T, tunnels chan<- string) net.Listener {
+	return startTCPServer(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		req, err := http.ReadRequest(reader)
+		if err != nil || req.Method != http.MethodConnect {
+			return
+		}
+		if req.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("asrt:secret")) {
+			conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\nContent-Length: 0\r\n\r\n"))
+			return
+		}
+		upstream, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\nContent-Length: 0\r\n\r\n"))
+			return
+		}
+		defer upstream.Close()
+		tunnels <- req.Host
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go io.Copy(upstream, reader)
+		io.Copy(conn, upstream)
+	})
+}
+
+func TestWebSocketThroughProxy(t *testing.T) {
+	tunnels := make(chan string, 2)
+	proxy := connectProxy(t, tunnels)
+	defer proxy.Close()
+
+	for _, scheme := range []string{"ws", "wss"} {
+		server := webSocketServer(scheme == "wss", echoServer)
+		target := wsTarget(server, scheme, "send=ping", "expect=echo: ping", "proxy=http://asrt:secret@"+proxy.Addr().String())
+		result := ExecuteTarget(target)
+		assert.Nil(t, result.Error, scheme)
+		assert.True(t, result.Success(), scheme)
+		assert.Equal(t, server.Listener.Addr().String(), <-tunnels, scheme)
+		server.Close()
+	}
+
+	server := webSocketServer(false, echoServer)
+	defer server.Close()
+	result := ExecuteTarget(wsTarget(server, "ws", "proxy=http://"+proxy.Addr().String()))
+	assert.False(t, result.Success())
+	assert.Contains(t, result.Error.Error(), "407 Proxy Authentication Required")
+
+	result = ExecuteTarget(wsTarget(server, "ws", "proxy=socks5://"+proxy.Addr().String()))
+	assert.False(t, result.Success())
+	assert.NotNil(t, result.Error)
+}
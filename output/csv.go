@@ -16,23 +16,27 @@ func NewCsvResultFormatter(m *ResultFormatModifiers) *CsvResultFormatter {
 
 func (rf *CsvResultFormatter) Header() io.Reader {
 	if !rf.Modifiers.NoHeader {
-		if rf.Modifiers.Markdown {
+		if rf.Modifiers.Report && !rf.Modifiers.Aggregate {
+			return reportHeader(rf.Modifiers, ",", "")
+		} else if rf.Modifiers.Wide && !rf.Modifiers.Aggregate {
+			return wideHeader(rf.Modifiers, ",", "")
+		} else if rf.Modifiers.Markdown {
 			if rf.Modifiers.Aggregate {
-				return strings.NewReader("*RESULT*,*COUNT*\n")
+				return strings.NewReader("*RESULT*,*COUNT*" + groupHeader(rf.Modifiers, ",") + "\n")
 			} else {
-				return strings.NewReader("*RESULT*,*EXPECT*,*ACTUAL*,*LABEL*,*URL*\n")
+				return strings.NewReader("*RESULT*,*EXPECT*,*ACTUAL*,*LABEL*,*URL*,*ASSERTION*\n")
 			}
 		} else if rf.Modifiers.Pretty {
 			if rf.Modifiers.Aggregate {
-				return strings.NewReader(fmt.Sprintf("%vRESULT%v,%vCOUNT%v\n", colorYellow, colorReset, colorYellow, colorReset))
+				return strings.NewReader(fmt.Sprintf("%vRESULT%v,%vCOUNT%v", colorYellow, colorReset, colorYellow, colorReset) + groupHeader(rf.Modifiers, ",") + "\n")
 			} else {
-				return strings.NewReader(fmt.Sprintf("%vRESULT%v,%vEXPECT%v,%vACTUAL%v,%vLABEL%v,%vURL%v\n", colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset))
+				return strings.NewReader(fmt.Sprintf("%vRESULT%v,%vEXPECT%v,%vACTUAL%v,%vLABEL%v,%vURL%v,%vASSERTION%v\n", colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset))
 			}
 		} else {
 			if rf.Modifiers.Aggregate {
-				return strings.NewReader("RESULT,COUNT\n")
+				return strings.NewReader("RESULT,COUNT" + groupHeader(rf.Modifiers, ",") + "\n")
 			} else {
-				return strings.NewReader("RESULT,EXPECT,ACTUAL,LABEL,URL\n")
+				return strings.NewReader("RESULT,EXPECT,ACTUAL,LABEL,URL,ASSERTION\n")
 			}
 		}
 	}
@@ -57,5 +61,8 @@ func (rf *CsvResultFormatter) Reader(result *Result) io.Reader {
 }
 
 func (rf *CsvResultFormatter) AggregateReader(results []*Result) io.Reader {
+	if rf.Modifiers.Groups {
+		return getResultStringGroupAggregateWithSeparator(results, rf.Modifiers, ",")
+	}
 	return getResultStringAggregateWithSeparator(results, rf.Modifiers, ",")
 }
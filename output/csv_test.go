@@ -1,62 +1,67 @@
 package output
 
 import (
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var csvTestSet = []*resultFormatTestCase{
 	{
-		expect:    "[ok],200,200,n/a,www",
+		expect:    "[ok],200,200,n/a,www,",
 		results:   []*Result{NewResult(true, nil, "200", "200", "www", "")},
 		modifiers: &ResultFormatModifiers{},
 		format:    csvFormat,
 	},
 	{
-		expect:    "[!ok],404,400,n/a,www.notfound.com",
+		expect:    "[!ok],404,400,n/a,www.notfound.com,",
 		results:   []*Result{NewResult(false, nil, "404", "400", "www.notfound.com", "")},
 		modifiers: &ResultFormatModifiers{},
 		format:    csvFormat,
 	},
 	{
-		expect:    "[err],500,n/a,n/a,www",
+		expect:    "[err],500,n/a,n/a,www,",
 		results:   []*Result{NewResult(false, errors.New("hi"), "500", "", "www", "")},
 		modifiers: &ResultFormatModifiers{},
 		format:    csvFormat,
 	},
 	{
-		expect:    fmt.Sprintf("%v[ok]%v,500,500,n/a,abc", colorGreen, colorReset),
+		expect:    fmt.Sprintf("%v[ok]%v,500,500,n/a,abc,", colorGreen, colorReset),
 		results:   []*Result{NewResult(true, nil, "500", "500", "abc", "")},
 		modifiers: &ResultFormatModifiers{Pretty: true},
 		format:    csvFormat,
 	},
 	{
-		expect:    fmt.Sprintf("%v[!ok]%v,404,500,n/a,abc", colorRed, colorReset),
+		expect:    fmt.Sprintf("%v[!ok]%v,404,500,n/a,abc,", colorRed, colorReset),
 		results:   []*Result{NewResult(false, nil, "404", "500", "abc", "")},
 		modifiers: &ResultFormatModifiers{Pretty: true},
 		format:    csvFormat,
 	},
 	{
-		expect:    fmt.Sprintf("%v[err]%v,500,n/a,n/a,abc", colorRed, colorReset),
+		expect:    fmt.Sprintf("%v[err]%v,500,n/a,n/a,abc,", colorRed, colorReset),
 		results:   []*Result{NewResult(false, fmt.Errorf("HELLO"), "500", "", "abc", "")},
 		modifiers: &ResultFormatModifiers{Pretty: true},
 		format:    csvFormat,
 	},
 	{
-		expect:    "*[ok]*,201,201,n/a,www",
+		expect:    "*[ok]*,201,201,n/a,www,",
 		results:   []*Result{NewResult(true, nil, "201", "201", "www", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true},
 		format:    csvFormat,
 	},
 	{
-		expect:    "*[err]*,201,201,n/a,www",
+		expect:    "*[err]*,201,201,n/a,www,",
 		results:   []*Result{NewResult(false, errors.New(""), "201", "201", "www", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true},
 		format:    csvFormat,
 	},
 	{
-		expect:    "*[!ok]*,200,201,n/a,www",
+		expect:    "*[!ok]*,200,201,n/a,www,",
 		results:   []*Result{NewResult(false, nil, "200", "201", "www", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true},
 		format:    csvFormat,
@@ -104,25 +109,25 @@ var csvTestSet = []*resultFormatTestCase{
 		format:    csvFormat,
 	},
 	{
-		expect:    fmt.Sprintf("*[ok]*,500,500,n/a,abc"),
+		expect:    fmt.Sprintf("*[ok]*,500,500,n/a,abc,"),
 		results:   []*Result{NewResult(true, nil, "500", "500", "abc", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true},
 		format:    csvFormat,
 	},
 	{
-		expect:    fmt.Sprintf("*[!ok]*,400,202,n/a,xyz"),
+		expect:    fmt.Sprintf("*[!ok]*,400,202,n/a,xyz,"),
 		results:   []*Result{NewResult(false, nil, "400", "202", "xyz", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true},
 		format:    csvFormat,
 	},
 	{
-		expect:    fmt.Sprintf("*[!ok]*,400,300,n/a,abcdef"),
+		expect:    fmt.Sprintf("*[!ok]*,400,300,n/a,abcdef,"),
 		results:   []*Result{NewResult(false, nil, "400", "300", "abcdef", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true},
 		format:    csvFormat,
 	},
 	{
-		expect:    fmt.Sprintf("*[err]*,301,n/a,n/a,abcd"),
+		expect:    fmt.Sprintf("*[err]*,301,n/a,n/a,abcd,"),
 		results:   []*Result{NewResult(false, fmt.Errorf("ABC"), "301", "", "abcd", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true},
 		format:    csvFormat,
@@ -145,6 +150,124 @@ var csvTestSet = []*resultFormatTestCase{
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true, NoHeader: true},
 		format:    csvFormat,
 	},
+	{
+		expect:    `[!ok],200,200,n/a,www,"body contains ""UP"""`,
+		results:   []*Result{&Result{Success: false, Expected: "200", Actual: "200", Url: "www", Assertion: `body contains "UP"`}},
+		modifiers: &ResultFormatModifiers{},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[!ok],www",
+		results:   []*Result{&Result{Success: false, Expected: "200", Actual: "200", Url: "www", Assertion: `body contains "UP"`}},
+		modifiers: &ResultFormatModifiers{NoHeader: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[warn],200,200,n/a,www,response time 620ms exceeds warn-after 500ms",
+		results:   []*Result{&Result{Success: true, Warning: true, Expected: "200", Actual: "200", Url: "www", Assertion: "response time 620ms exceeds warn-after 500ms"}},
+		modifiers: &ResultFormatModifiers{},
+		format:    csvFormat,
+	},
+	{
+		expect:    fmt.Sprintf("%v[warn]%v,www", colorYellow, colorReset),
+		results:   []*Result{&Result{Success: true, Warning: true, Expected: "200", Actual: "200", Url: "www"}},
+		modifiers: &ResultFormatModifiers{NoHeader: true, Pretty: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    fmt.Sprintf("%v[warn]%v,2", colorYellow, colorReset),
+		results:   []*Result{&Result{Success: true, Warning: true, Url: "www"}, &Result{Success: true, Url: "abc"}},
+		modifiers: &ResultFormatModifiers{Aggregate: true, Pretty: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[!ok],2",
+		results:   []*Result{&Result{Success: true, Warning: true, Url: "www"}, &Result{Success: false, Url: "abc"}},
+		modifiers: &ResultFormatModifiers{Aggregate: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[ok],200,200,152ms,3ms,10ms,25ms,100ms,14ms,n/a,www,",
+		results:   []*Result{&Result{Success: true, Expected: "200", Actual: "200", Url: "www", Timings: testTimings}},
+		modifiers: &ResultFormatModifiers{Wide: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "*[err]*,200,n/a,n/a,n/a,n/a,n/a,n/a,n/a,n/a,www,",
+		results:   []*Result{NewResult(false, errors.New("hi"), "200", "", "www", "")},
+		modifiers: &ResultFormatModifiers{Wide: true, Markdown: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[ok],www",
+		results:   []*Result{&Result{Success: true, Expected: "200", Actual: "200", Url: "www", Timings: testTimings}},
+		modifiers: &ResultFormatModifiers{Wide: true, NoHeader: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[ok],\"200,204\",204,n/a,www,",
+		results:   []*Result{NewResult(true, nil, "200,204", "204", "www", "")},
+		modifiers: &ResultFormatModifiers{},
+		format:    csvFormat,
+	},
+	{
+		expect:    "*[!ok]*,2xx,404,n/a,www,",
+		results:   []*Result{NewResult(false, nil, "2xx", "404", "www", "")},
+		modifiers: &ResultFormatModifiers{Markdown: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[flap],200,500,n/a,www,",
+		results:   []*Result{&Result{Success: false, Flapping: true, Expected: "200", Actual: "500", Url: "www"}},
+		modifiers: &ResultFormatModifiers{},
+		format:    csvFormat,
+	},
+	{
+		expect:    "\033[1;34m[flap]\033[0m,www",
+		results:   []*Result{&Result{Success: true, Flapping: true, Url: "www"}},
+		modifiers: &ResultFormatModifiers{Pretty: true, NoHeader: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[ok],99.95%,99.9%,n/a,n/a,12ms,80ms,1.2s,-25%,lbl,www",
+		results:   []*Result{&Result{Success: true, Url: "www", Label: "lbl", Report: testReport}},
+		modifiers: &ResultFormatModifiers{Report: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[!ok],n/a,n/a,n/a,n/a,n/a,n/a,n/a,n/a,n/a,www",
+		results:   []*Result{&Result{Url: "www"}},
+		modifiers: &ResultFormatModifiers{Report: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[!ok],2,payments\n[ok],1,n/a",
+		results:   []*Result{&Result{Success: true, Url: "www", Group: "payments"}, &Result{Success: true, Url: "abc"}, &Result{Success: false, Url: "def", Group: "payments"}},
+		modifiers: &ResultFormatModifiers{Aggregate: true, Groups: true},
+		format:    csvFormat,
+	},
+	{
+		expect:    "[warn],payments",
+		results:   []*Result{&Result{Success: true, Warning: true, Url: "www", Group: "payments"}},
+		modifiers: &ResultFormatModifiers{Aggregate: true, Groups: true, NoHeader: true},
+		format:    csvFormat,
+	},
+}
+
+var testReport = &Report{
+	Uptime1h:    &Uptime{Percent: 99.95, Checks: 2000, Failures: 1},
+	Uptime24h:   &Uptime{Percent: 99.9, Checks: 1000, Failures: 1},
+	Latency24h:  &Latency{P50: Duration(12 * time.Millisecond), P95: Duration(80 * time.Millisecond), P99: Duration(1200 * time.Millisecond)},
+	ErrorBudget: &ErrorBudget{SLO: 99.9, Remaining: -25},
+}
+
+var testTimings = &Timings{
+	DNS:       Duration(3 * time.Millisecond),
+	Connect:   Duration(10 * time.Millisecond),
+	TLS:       Duration(25 * time.Millisecond),
+	FirstByte: Duration(100 * time.Millisecond),
+	Transfer:  Duration(14 * time.Millisecond),
+	Total:     Duration(152 * time.Millisecond),
 }
 
 func TestCsvResultFormats(t *testing.T) {
@@ -152,3 +275,42 @@ func TestCsvResultFormats(t *testing.T) {
 		runResultFormatTestCase(t, testcase)
 	}
 }
+
+func TestCsvReportHeader(t *testing.T) {
+	rf := NewCsvResultFormatter(&ResultFormatModifiers{Report: true})
+	assert.Equal(t, "RESULT,UP-1H,UP-24H,UP-7D,UP-30D,P50,P95,P99,BUDGET,LABEL,URL\n", readersToString(rf.Header()))
+}
+
+func TestCsvWideHeader(t *testing.T) {
+	rf := NewCsvResultFormatter(&ResultFormatModifiers{Wide: true})
+	assert.Equal(t, "RESULT,EXPECT,ACTUAL,TOTAL,DNS,CONNECT,TLS,TTFB,XFER,LABEL,URL,ASSERTION\n", readersToString(rf.Header()))
+
+	rf = NewCsvResultFormatter(&ResultFormatModifiers{Wide: true, Aggregate: true})
+	assert.Equal(t, "RESULT,COUNT\n", readersToString(rf.Header()))
+}
+
+func TestCsvAssertionColumn(t *testing.T) {
+	rf := NewCsvResultFormatter(&ResultFormatModifiers{})
+	assertion := `header Cache-Control == "no-cache" (observed "no-cache, no-store")`
+	result := &Result{Success: false, Expected: "200", Actual: "200", Url: "www", Assertion: assertion}
+
+	records, err := csv.NewReader(strings.NewReader(readersToString(rf.Header(), rf.Reader(result)))).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, []string{"RESULT", "EXPECT", "ACTUAL", "LABEL", "URL", "ASSERTION"}, records[0])
+	assert.Equal(t, []string{"[!ok]", "200", "200", "n/a", "www", assertion}, records[1])
+}
+
+func TestCsvGroupAggregateHeader(t *testing.T) {
+	rf := NewCsvResultFormatter(&ResultFormatModifiers{Aggregate: true, Groups: true})
+	assert.Equal(t, "RESULT,COUNT,GROUP\n", readersToString(rf.Header()))
+
+	rf = NewCsvResultFormatter(&ResultFormatModifiers{Aggregate: true, Groups: true, Markdown: true})
+	assert.Equal(t, "*RESULT*,*COUNT*,*GROUP*\n", readersToString(rf.Header()))
+}
+
+func TestJsonGroupAggregate(t *testing.T) {
+	rf := NewJsonResultFormatter(&ResultFormatModifiers{Aggregate: true, Groups: true})
+	results := []*Result{&Result{Success: false, Url: "abc", Group: "search"}, &Result{Success: true, Url: "www", Group: "payments"}}
+	assert.Equal(t, `[{"ok":true,"count":1,"group":"payments"},{"ok":false,"count":1,"group":"search"}]`, readersToString(rf.AggregateReader(results)))
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const groupNone string = "n/a"
+
+// groupResults splits results by target group. Groups are sorted by name, with targets without a group last,
+// since results arrive in no particular order.
+func groupResults(results []*Result) [][]*Result {
+	byGroup := make(map[string][]*Result)
+	var names []string
+	for _, r := range results {
+		if _, ok := byGroup[r.Group]; !ok {
+			names = append(names, r.Group)
+		}
+		byGroup[r.Group] = append(byGroup[r.Group], r)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "" || names[j] == "" {
+			return names[j] == "" && names[i] != ""
+		}
+		return names[i] < names[j]
+	})
+
+	var groups [][]*Result
+	for _, name := range names {
+		groups = append(groups, byGroup[name])
+	}
+	return groups
+}
+
+func groupName(results []*Result) string {
+	if len(results) == 0 || results[0].Group == "" {
+		return groupNone
+	}
+	return results[0].Group
+}
+
+func newGroupAggregateResults(results []*Result) []*aggregateResult {
+	var aggregates []*aggregateResult
+	for _, group := range groupResults(results) {
+		aggResult := newAggregateResult(group)
+		aggResult.Group = groupName(group)
+		aggregates = append(aggregates, aggResult)
+	}
+	return aggregates
+}
+
+func newGroupAggregateQuietResults(results []*Result) []*quietAggregateResult {
+	var aggregates []*quietAggregateResult
+	for _, group := range groupResults(results) {
+		aggResult := newAggregateQuietResult(group)
+		aggResult.Group = groupName(group)
+		aggregates = append(aggregates, aggResult)
+	}
+	return aggregates
+}
+
+// groupHeader is the trailing GROUP column of aggregate headers, when aggregating by group
+func groupHeader(modifiers *ResultFormatModifiers, separator string) string {
+	switch {
+	case !modifiers.Groups:
+		return ""
+	case modifiers.Markdown:
+		return separator + "*GROUP*"
+	case modifiers.Pretty:
+		return fmt.Sprintf("%v%vGROUP%v", separator, colorYellow, colorReset)
+	}
+	return separator + "GROUP"
+}
+
+// getResultStringGroupAggregateWithSeparator writes one aggregate line per group, followed by the group name
+func getResultStringGroupAggregateWithSeparator(results []*Result, modifiers *ResultFormatModifiers, separator string) io.Reader {
+	var lines []string
+	for _, group := range groupResults(results) {
+		s := readersToLine(getResultStringAggregateWithSeparator(group, modifiers, separator))
+		lines = append(lines, fmt.Sprintf("%v%v%v", s, separator, groupName(group)))
+	}
+	return strings.NewReader(strings.Join(lines, "\n"))
+}
+
+func readersToLine(reader io.Reader) string {
+	var b strings.Builder
+	io.Copy(&b, reader)
+	return b.String()
+}
@@ -44,7 +44,11 @@ func (rf *JsonResultFormatter) Footer() io.Reader {
 }
 
 func (rf *JsonResultFormatter) AggregateReader(results []*Result) io.Reader {
-	if rf.Modifiers.NoHeader {
+	if rf.Modifiers.Groups && rf.Modifiers.NoHeader {
+		return rf.getReaderForInterface(newGroupAggregateQuietResults(results))
+	} else if rf.Modifiers.Groups {
+		return rf.getReaderForInterface(newGroupAggregateResults(results))
+	} else if rf.Modifiers.NoHeader {
 		return rf.getReaderForInterface(newAggregateQuietResult(results))
 	} else {
 		return rf.getReaderForInterface(newAggregateResult(results))
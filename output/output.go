@@ -12,17 +12,32 @@ const (
 	statusTextOk    string = "[ok]"
 	statusTextNotOk string = "[!ok]"
 	statusTextError string = "[err]"
+	statusTextWarn  string = "[warn]"
+	statusTextFlap  string = "[flap]"
 )
 
 type Result struct {
-	Success   bool                   `json:"ok"`
-	Error     error                  `json:"error,omitempty"`
-	Expected  string                 `json:"expectation,omitempty"`
-	Actual    string                 `json:"actual,omitempty"`
-	Url       string                 `json:"url,omitempty"`
-	Label     string                 `json:"label,omitempty"`
-	Timestamp string                 `json:"timestamp,omitempty"`
-	Extra     map[string]interface{} `json:"extra,omitempty"`
+	Success      bool                   `json:"ok"`
+	Warning      bool                   `json:"warn,omitempty"`
+	Flapping     bool                   `json:"flapping,omitempty"`
+	Error        error                  `json:"error,omitempty"`
+	Expected     string                 `json:"expectation,omitempty"`
+	Actual       string                 `json:"actual,omitempty"`
+	Url          string                 `json:"url,omitempty"`
+	Label        string                 `json:"label,omitempty"`
+	Group        string                 `json:"group,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	Assertion    string                 `json:"assertion,omitempty"`
+	ResponseTime Duration               `json:"responseTime,omitempty"`
+	Timings      *Timings               `json:"timings,omitempty"`
+	Certificate  *CertificateInfo       `json:"certificate,omitempty"`
+	Redirects    []Redirect             `json:"redirects,omitempty"`
+	FinalURL     string                 `json:"finalUrl,omitempty"`
+	Attempts     int                    `json:"attempts,omitempty"`
+	Failures     []string               `json:"failedAttempts,omitempty"`
+	Report       *Report                `json:"report,omitempty"`
+	Timestamp    string                 `json:"timestamp,omitempty"`
+	Extra        map[string]interface{} `json:"extra,omitempty"`
 }
 
 func NewResult(success bool, err error, expected string, actual string, url string, label string) *Result {
@@ -56,44 +71,57 @@ type ResultFormatModifiers struct {
 	Aggregate bool
 	NoHeader  bool
 	Markdown  bool
+	Wide      bool
+	Report    bool
+	Groups    bool // aggregate one result per target group
 }
 
 type quietResult struct {
-	Success bool   `json:"ok"`
-	Url     string `json:"url,omitempty"`
+	Success  bool   `json:"ok"`
+	Warning  bool   `json:"warn,omitempty"`
+	Flapping bool   `json:"flapping,omitempty"`
+	Url      string `json:"url,omitempty"`
 }
 
 type quietAggregateResult struct {
-	Success bool `json:"ok"`
+	Success bool   `json:"ok"`
+	Warning bool   `json:"warn,omitempty"`
+	Group   string `json:"group,omitempty"`
 }
 
 type aggregateResult struct {
-	Success bool `json:"ok"`
-	Count   int  `json:"count,omitempty"`
+	Success bool   `json:"ok"`
+	Warning bool   `json:"warn,omitempty"`
+	Count   int    `json:"count,omitempty"`
+	Group   string `json:"group,omitempty"`
 }
 
 func newQuietResult(result *Result) *quietResult {
-	return &quietResult{Success: result.Success, Url: result.Url}
+	return &quietResult{Success: result.Success, Warning: result.Success && result.Warning, Flapping: result.Flapping, Url: result.Url}
 }
 
 func newAggregateQuietResult(results []*Result) *quietAggregateResult {
-	success := true
-	for _, r := range results {
-		if !r.Success {
-			success = false
-		}
-	}
-	return &quietAggregateResult{Success: success}
+	success, warning := aggregateSuccessAndWarning(results)
+	return &quietAggregateResult{Success: success, Warning: warning}
 }
 
 func newAggregateResult(results []*Result) *aggregateResult {
+	success, warning := aggregateSuccessAndWarning(results)
+	return &aggregateResult{Success: success, Warning: warning, Count: len(results)}
+}
+
+// aggregateSuccessAndWarning is only a warning when every result succeeded and at least one warned
+func aggregateSuccessAndWarning(results []*Result) (bool, bool) {
 	success := true
+	warning := false
 	for _, r := range results {
 		if !r.Success {
 			success = false
+		} else if r.Warning {
+			warning = true
 		}
 	}
-	return &aggregateResult{Success: success, Count: len(results)}
+	return success, success && warning
 }
 
 type StatusMessager interface {
@@ -108,6 +136,12 @@ func (result *Result) StatusCodeActual() string {
 }
 
 func (result *Result) StatusMessage() string {
+	if result.Flapping {
+		return statusTextFlap
+	}
+	if result.Success && result.Warning {
+		return statusTextWarn
+	}
 	if result.Success {
 		return statusTextOk
 	}
@@ -118,7 +152,11 @@ func (result *Result) StatusMessage() string {
 }
 
 func (result *quietResult) StatusMessage() string {
-	if result.Success {
+	if result.Flapping {
+		return statusTextFlap
+	} else if result.Success && result.Warning {
+		return statusTextWarn
+	} else if result.Success {
 		return statusTextOk
 	} else {
 		return statusTextNotOk
@@ -126,7 +164,9 @@ func (result *quietResult) StatusMessage() string {
 }
 
 func (result *quietAggregateResult) StatusMessage() string {
-	if result.Success {
+	if result.Success && result.Warning {
+		return statusTextWarn
+	} else if result.Success {
 		return statusTextOk
 	} else {
 		return statusTextNotOk
@@ -134,9 +174,45 @@ func (result *quietAggregateResult) StatusMessage() string {
 }
 
 func (result *aggregateResult) StatusMessage() string {
-	if result.Success {
+	if result.Success && result.Warning {
+		return statusTextWarn
+	} else if result.Success {
 		return statusTextOk
 	} else {
 		return statusTextNotOk
 	}
 }
+
+// colorForResult shows flapping targets in blue, whatever their latest result
+func colorForResult(result *Result) string {
+	if result.Flapping {
+		return colorBlue
+	}
+	return colorForStatus(result.Success && result.Error == nil, result.Warning)
+}
+
+func colorForStatus(success bool, warning bool) string {
+	if !success {
+		return colorRed
+	} else if warning {
+		return colorYellow
+	}
+	return colorGreen
+}
+
+// CertificateInfo describes the certificate a target presented during its TLS handshake.
+type CertificateInfo struct {
+	Subject       string   `json:"subject,omitempty"`
+	Issuer        string   `json:"issuer,omitempty"`
+	SANs          []string `json:"sans,omitempty"`
+	NotAfter      string   `json:"notAfter,omitempty"`
+	DaysRemaining int      `json:"daysRemaining"`
+	Expired       bool     `json:"expired,omitempty"`
+}
+
+// Redirect is one hop of the redirect chain a target followed.
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status"`
+	Location   string `json:"location"`
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var reportColumns = []string{"UP-1H", "UP-24H", "UP-7D", "UP-30D", "P50", "P95", "P99", "BUDGET"}
+
+// Report summarizes the history of a target.
+type Report struct {
+	Uptime1h    *Uptime      `json:"uptime1h,omitempty"`
+	Uptime24h   *Uptime      `json:"uptime24h,omitempty"`
+	Uptime7d    *Uptime      `json:"uptime7d,omitempty"`
+	Uptime30d   *Uptime      `json:"uptime30d,omitempty"`
+	Latency24h  *Latency     `json:"latency24h,omitempty"`
+	ErrorBudget *ErrorBudget `json:"errorBudget,omitempty"`
+}
+
+// Uptime is the share of successful results within a window. Warnings count as up.
+type Uptime struct {
+	Percent  float64 `json:"percent"`
+	Checks   int     `json:"checks"`
+	Failures int     `json:"failures"`
+}
+
+func (u *Uptime) String() string {
+	if u == nil || u.Checks == 0 {
+		return "n/a"
+	}
+	return formatPercent(u.Percent)
+}
+
+// Latency holds response time percentiles of successful results.
+type Latency struct {
+	P50 Duration `json:"p50"`
+	P90 Duration `json:"p90"`
+	P95 Duration `json:"p95"`
+	P99 Duration `json:"p99"`
+}
+
+// ErrorBudget is how many failures the SLO allows over its window and how much of that is left.
+// Remaining is a percentage of the budget and goes negative once the SLO is missed.
+type ErrorBudget struct {
+	SLO       float64 `json:"slo"`
+	Window    string  `json:"window"`
+	Allowed   float64 `json:"allowed"`
+	Failures  int     `json:"failures"`
+	Remaining float64 `json:"remaining"`
+}
+
+func (b *ErrorBudget) String() string {
+	if b == nil {
+		return "n/a"
+	}
+	return formatPercent(b.Remaining)
+}
+
+func formatPercent(p float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.3f", p), "0"), ".") + "%"
+}
+
+// reportHeader builds the header for results of the report command.
+// labelSuffix lets the tab format keep its extra label padding.
+func reportHeader(modifiers *ResultFormatModifiers, separator string, labelSuffix string) io.Reader {
+	columns := append([]string{"RESULT"}, reportColumns...)
+	columns = append(columns, "LABEL", "URL")
+
+	for i, c := range columns {
+		switch {
+		case modifiers.Markdown:
+			columns[i] = fmt.Sprintf("*%v*", c)
+		case modifiers.Pretty:
+			columns[i] = fmt.Sprintf("%v%v%v", colorYellow, c, colorReset)
+		}
+		if c == "LABEL" {
+			columns[i] += labelSuffix
+		}
+	}
+	return strings.NewReader(strings.Join(columns, separator) + "\n")
+}
+
+// Normal Result
+// +report
+func separatorReportResult(result *Result, modifiers *ResultFormatModifiers, separator string) string {
+	var status string
+	switch {
+	case modifiers.Markdown:
+		status = fmt.Sprintf("*%v*", result.StatusMessage())
+	case modifiers.Pretty:
+		status = fmt.Sprintf("%v%v%v", colorForResult(result), result.StatusMessage(), colorReset)
+	default:
+		status = result.StatusMessage()
+	}
+
+	columns := append([]string{status}, reportValueColumns(result.Report)...)
+	columns = append(columns, result.Label, result.Url)
+	return strings.Join(columns, separator)
+}
+
+func reportValueColumns(r *Report) []string {
+	if r == nil {
+		r = &Report{}
+	}
+	latency := []string{"n/a", "n/a", "n/a"}
+	if r.Latency24h != nil {
+		latency = []string{r.Latency24h.P50.String(), r.Latency24h.P95.String(), r.Latency24h.P99.String()}
+	}
+
+	columns := []string{r.Uptime1h.String(), r.Uptime24h.String(), r.Uptime7d.String(), r.Uptime30d.String()}
+	columns = append(columns, latency...)
+	return append(columns, r.ErrorBudget.String())
+}
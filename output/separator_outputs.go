@@ -10,6 +10,10 @@ func getResultStringWithSeparator(result *Result, modifiers *ResultFormatModifie
 	var s string
 
 	switch {
+	case modifiers.Report && !modifiers.NoHeader:
+		s = separatorReportResult(result, modifiers, separator)
+	case modifiers.Wide && !modifiers.NoHeader:
+		s = separatorWideResult(result, modifiers, separator)
 	case modifiers.Markdown && !modifiers.NoHeader:
 		s = separatorResultForMarkdown(result, separator)
 	case modifiers.Markdown && modifiers.NoHeader:
@@ -48,32 +52,41 @@ func getResultStringAggregateWithSeparator(results []*Result, modifiers *ResultF
 	return strings.NewReader(s)
 }
 
+// quoteIfContains keeps values such as the status expectation 200,204 or an assertion on a quoted body in a
+// single column. Quoted values have their quotes doubled, the way csv escapes them. Quotes alone only need
+// quoting in csv, so that tab output stays readable.
+func quoteIfContains(value string, separator string) string {
+	if strings.Contains(value, separator) || strings.Contains(value, "\n") || (separator == "," && strings.Contains(value, `"`)) {
+		return `"` + strings.Replace(value, `"`, `""`, -1) + `"`
+	}
+	return value
+}
+
+// assertionColumn adds the failed assertion as the trailing column, which is empty when there is none
+func assertionColumn(result *Result, separator string) string {
+	return separator + quoteIfContains(result.Assertion, separator)
+}
+
 // Normal Result
 // +pretty
 // +quiet
 func separatorResultForQuietPretty(result *Result, separator string) string {
-	statusColor := colorGreen
-	if !result.Success || result.Error != nil {
-		statusColor = colorRed
-	}
+	statusColor := colorForResult(result)
 
 	return fmt.Sprintf("%v%v%v%v%v", statusColor, result.StatusMessage(), colorReset, separator, result.Url)
 }
 
 // Normal Result
 func separatorResult(result *Result, separator string) string {
-	return fmt.Sprintf("%v%v%v%v%v%v%v%v%v", result.StatusMessage(), separator, result.Expected, separator, result.StatusCodeActual(), separator, result.Label, separator, result.Url)
+	return fmt.Sprintf("%v%v%v%v%v%v%v%v%v%v", result.StatusMessage(), separator, quoteIfContains(result.Expected, separator), separator, result.StatusCodeActual(), separator, result.Label, separator, result.Url, assertionColumn(result, separator))
 }
 
 // Normal Result
 // +pretty
 func separatorResultForPretty(result *Result, separator string) string {
-	statusColor := colorGreen
-	if !result.Success || result.Error != nil {
-		statusColor = colorRed
-	}
+	statusColor := colorForResult(result)
 
-	return fmt.Sprintf("%v%v%v%v%v%v%v%v%v%v%v", statusColor, result.StatusMessage(), colorReset, separator, result.Expected, separator, result.StatusCodeActual(), separator, result.Label, separator, result.Url)
+	return fmt.Sprintf("%v%v%v%v%v%v%v%v%v%v%v%v", statusColor, result.StatusMessage(), colorReset, separator, quoteIfContains(result.Expected, separator), separator, result.StatusCodeActual(), separator, result.Label, separator, result.Url, assertionColumn(result, separator))
 }
 
 // Normal Result
@@ -85,7 +98,7 @@ func separatorResultForQuiet(result *Result, separator string) string {
 // Normal Result
 // +markdown
 func separatorResultForMarkdown(result *Result, separator string) string {
-	return fmt.Sprintf("*%v*%v%v%v%v%v%v%v%v", result.StatusMessage(), separator, result.Expected, separator, result.StatusCodeActual(), separator, result.Label, separator, result.Url)
+	return fmt.Sprintf("*%v*%v%v%v%v%v%v%v%v%v", result.StatusMessage(), separator, quoteIfContains(result.Expected, separator), separator, result.StatusCodeActual(), separator, result.Label, separator, result.Url, assertionColumn(result, separator))
 }
 
 // Normal Result
@@ -106,10 +119,7 @@ func separatorAggregateResult(results []*Result, separator string) string {
 func separatorAggregateResultForPretty(results []*Result, separator string) string {
 	aggResult := newAggregateResult(results)
 
-	statusColor := colorGreen
-	if !aggResult.Success {
-		statusColor = colorRed
-	}
+	statusColor := colorForStatus(aggResult.Success, aggResult.Warning)
 
 	return fmt.Sprintf("%v%v%v%v%v", statusColor, aggResult.StatusMessage(), colorReset, separator, aggResult.Count)
 }
@@ -127,10 +137,7 @@ func separatorAggregateResultForQuiet(results []*Result, separator string) strin
 func separatorAggregateResultForQuietPretty(results []*Result, separator string) string {
 	aggResult := newAggregateQuietResult(results)
 
-	statusColor := colorGreen
-	if !aggResult.Success {
-		statusColor = colorRed
-	}
+	statusColor := colorForStatus(aggResult.Success, aggResult.Warning)
 
 	return fmt.Sprintf("%v%v%v", statusColor, aggResult.StatusMessage(), colorReset)
 }
@@ -16,23 +16,27 @@ func NewTabResultFormatter(m *ResultFormatModifiers) *TabResultFormatter {
 
 func (rf *TabResultFormatter) Header() io.Reader {
 	if !rf.Modifiers.NoHeader {
-		if rf.Modifiers.Markdown {
+		if rf.Modifiers.Report && !rf.Modifiers.Aggregate {
+			return reportHeader(rf.Modifiers, "\t", "\t\t")
+		} else if rf.Modifiers.Wide && !rf.Modifiers.Aggregate {
+			return wideHeader(rf.Modifiers, "\t", "\t\t")
+		} else if rf.Modifiers.Markdown {
 			if rf.Modifiers.Aggregate {
-				return strings.NewReader("*RESULT*\t*COUNT*\n")
+				return strings.NewReader("*RESULT*\t*COUNT*" + groupHeader(rf.Modifiers, "\t") + "\n")
 			} else {
-				return strings.NewReader("*RESULT*\t*EXPECT*\t*ACTUAL*\t*LABEL*\t\t\t*URL*\n")
+				return strings.NewReader("*RESULT*\t*EXPECT*\t*ACTUAL*\t*LABEL*\t\t\t*URL*\t*ASSERTION*\n")
 			}
 		} else if rf.Modifiers.Pretty {
 			if rf.Modifiers.Aggregate {
-				return strings.NewReader(fmt.Sprintf("%vRESULT%v\t%vCOUNT%v\n", colorYellow, colorReset, colorYellow, colorReset))
+				return strings.NewReader(fmt.Sprintf("%vRESULT%v\t%vCOUNT%v", colorYellow, colorReset, colorYellow, colorReset) + groupHeader(rf.Modifiers, "\t") + "\n")
 			} else {
-				return strings.NewReader(fmt.Sprintf("%vRESULT%v\t%vEXPECT%v\t%vACTUAL%v\t%vLABEL%v\t\t\t%vURL%v\n", colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset))
+				return strings.NewReader(fmt.Sprintf("%vRESULT%v\t%vEXPECT%v\t%vACTUAL%v\t%vLABEL%v\t\t\t%vURL%v\t%vASSERTION%v\n", colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset, colorYellow, colorReset))
 			}
 		} else {
 			if rf.Modifiers.Aggregate {
-				return strings.NewReader("RESULT\tCOUNT\n")
+				return strings.NewReader("RESULT\tCOUNT" + groupHeader(rf.Modifiers, "\t") + "\n")
 			} else {
-				return strings.NewReader("RESULT\tEXPECT\tACTUAL\tLABEL\t\t\tURL\n")
+				return strings.NewReader("RESULT\tEXPECT\tACTUAL\tLABEL\t\t\tURL\tASSERTION\n")
 			}
 		}
 	}
@@ -62,5 +66,8 @@ func (rf *TabResultFormatter) Reader(result *Result) io.Reader {
 }
 
 func (rf *TabResultFormatter) AggregateReader(results []*Result) io.Reader {
+	if rf.Modifiers.Groups {
+		return getResultStringGroupAggregateWithSeparator(results, rf.Modifiers, "\t")
+	}
 	return getResultStringAggregateWithSeparator(results, rf.Modifiers, "\t")
 }
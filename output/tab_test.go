@@ -8,55 +8,55 @@ import (
 
 var tabTestSet = []*resultFormatTestCase{
 	{
-		expect:    "[ok]\t200\t200\t\t\twww",
+		expect:    "[ok]\t200\t200\t\t\twww\t",
 		results:   []*Result{NewResult(true, nil, "200", "200", "www", "")},
 		modifiers: &ResultFormatModifiers{},
 		format:    tabFormat,
 	},
 	{
-		expect:    "[!ok]\t404\t406\t\t\twww.notfound.com",
+		expect:    "[!ok]\t404\t406\t\t\twww.notfound.com\t",
 		results:   []*Result{NewResult(false, nil, "404", "406", "www.notfound.com", "")},
 		modifiers: &ResultFormatModifiers{},
 		format:    tabFormat,
 	},
 	{
-		expect:    "[err]\t500\tn/a\t\t\twww",
+		expect:    "[err]\t500\tn/a\t\t\twww\t",
 		results:   []*Result{NewResult(false, errors.New("hi"), "500", "", "www", "")},
 		modifiers: &ResultFormatModifiers{},
 		format:    tabFormat,
 	},
 	{
-		expect:    fmt.Sprintf("%v[ok]%v\t500\t500\t\t\tabc", colorGreen, colorReset),
+		expect:    fmt.Sprintf("%v[ok]%v\t500\t500\t\t\tabc\t", colorGreen, colorReset),
 		results:   []*Result{NewResult(true, nil, "500", "500", "abc", "")},
 		modifiers: &ResultFormatModifiers{Pretty: true},
 		format:    tabFormat,
 	},
 	{
-		expect:    fmt.Sprintf("%v[!ok]%v\t500\t406\t\t\tabc", colorRed, colorReset),
+		expect:    fmt.Sprintf("%v[!ok]%v\t500\t406\t\t\tabc\t", colorRed, colorReset),
 		results:   []*Result{NewResult(false, nil, "500", "406", "abc", "")},
 		modifiers: &ResultFormatModifiers{Pretty: true},
 		format:    tabFormat,
 	},
 	{
-		expect:    fmt.Sprintf("%v[err]%v\t500\tn/a\t\t\tabc", colorRed, colorReset),
+		expect:    fmt.Sprintf("%v[err]%v\t500\tn/a\t\t\tabc\t", colorRed, colorReset),
 		results:   []*Result{NewResult(false, fmt.Errorf("HELLO"), "500", "", "abc", "")},
 		modifiers: &ResultFormatModifiers{Pretty: true},
 		format:    tabFormat,
 	},
 	{
-		expect:    "*[ok]*\t201\t201\t\t\twww",
+		expect:    "*[ok]*\t201\t201\t\t\twww\t",
 		results:   []*Result{NewResult(true, nil, "201", "201", "www", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true},
 		format:    tabFormat,
 	},
 	{
-		expect:    "*[err]*\t201\tn/a\t\t\twww",
+		expect:    "*[err]*\t201\tn/a\t\t\twww\t",
 		results:   []*Result{NewResult(false, errors.New(""), "201", "", "www", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true},
 		format:    tabFormat,
 	},
 	{
-		expect:    "*[!ok]*\t201\t406\t\t\twww",
+		expect:    "*[!ok]*\t201\t406\t\t\twww\t",
 		results:   []*Result{NewResult(false, nil, "201", "406", "www", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true},
 		format:    tabFormat,
@@ -104,25 +104,25 @@ var tabTestSet = []*resultFormatTestCase{
 		format:    tabFormat,
 	},
 	{
-		expect:    fmt.Sprintf("*[ok]*\t500\t500\t\t\tabc"),
+		expect:    fmt.Sprintf("*[ok]*\t500\t500\t\t\tabc\t"),
 		results:   []*Result{NewResult(true, nil, "500", "500", "abc", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true},
 		format:    tabFormat,
 	},
 	{
-		expect:    fmt.Sprintf("*[!ok]*\t202\t406\t\t\txyz"),
+		expect:    fmt.Sprintf("*[!ok]*\t202\t406\t\t\txyz\t"),
 		results:   []*Result{NewResult(false, nil, "202", "406", "xyz", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true},
 		format:    tabFormat,
 	},
 	{
-		expect:    fmt.Sprintf("*[!ok]*\t300\t406\t\t\tabcdef"),
+		expect:    fmt.Sprintf("*[!ok]*\t300\t406\t\t\tabcdef\t"),
 		results:   []*Result{NewResult(false, nil, "300", "406", "abcdef", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true},
 		format:    tabFormat,
 	},
 	{
-		expect:    fmt.Sprintf("*[err]*\t301\tn/a\t\t\tabcd"),
+		expect:    fmt.Sprintf("*[err]*\t301\tn/a\t\t\tabcd\t"),
 		results:   []*Result{NewResult(false, fmt.Errorf("ABC"), "301", "", "abcd", "")},
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true},
 		format:    tabFormat,
@@ -145,6 +145,30 @@ var tabTestSet = []*resultFormatTestCase{
 		modifiers: &ResultFormatModifiers{Markdown: true, Pretty: true, NoHeader: true},
 		format:    tabFormat,
 	},
+	{
+		expect:    fmt.Sprintf("%v[!ok]%v\t200\t200\t\t\twww\theader X-Backend-Health == \"ok\" (observed \"degraded\")", colorRed, colorReset),
+		results:   []*Result{&Result{Success: false, Expected: "200", Actual: "200", Url: "www", Assertion: `header X-Backend-Health == "ok" (observed "degraded")`}},
+		modifiers: &ResultFormatModifiers{Pretty: true},
+		format:    tabFormat,
+	},
+	{
+		expect:    "*[!ok]*\t200\t200\t\t\twww\theader X-Backend-Health exists (observed missing)",
+		results:   []*Result{&Result{Success: false, Expected: "200", Actual: "200", Url: "www", Assertion: "header X-Backend-Health exists (observed missing)"}},
+		modifiers: &ResultFormatModifiers{Markdown: true},
+		format:    tabFormat,
+	},
+	{
+		expect:    fmt.Sprintf("%v[ok]%v\t200\t200\t152ms\t3ms\t10ms\t25ms\t100ms\t14ms\t\t\twww\t", colorGreen, colorReset),
+		results:   []*Result{&Result{Success: true, Expected: "200", Actual: "200", Url: "www", Timings: testTimings}},
+		modifiers: &ResultFormatModifiers{Wide: true, Pretty: true},
+		format:    tabFormat,
+	},
+	{
+		expect:    "[ok]\t200,204\t204\t\t\twww\t",
+		results:   []*Result{NewResult(true, nil, "200,204", "204", "www", "")},
+		modifiers: &ResultFormatModifiers{},
+		format:    tabFormat,
+	},
 }
 
 func TestTabResultFormats(t *testing.T) {
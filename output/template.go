@@ -108,6 +108,15 @@ func (rf *TemplateResultFormatter) AggregateReader(results []*Result) io.Reader
 		return strings.NewReader("")
 	}
 	buffer := new(bytes.Buffer)
+	if rf.Modifiers.Groups {
+		for i, aggResult := range newGroupAggregateQuietResults(results) {
+			if i > 0 {
+				buffer.WriteString("\n")
+			}
+			rf.OutputTemplate.Execute(buffer, aggResult)
+		}
+		return buffer
+	}
 	rf.OutputTemplate.Execute(buffer, newAggregateQuietResult(results))
 	return buffer
 }
@@ -70,3 +70,14 @@ func readerToString(reader io.Reader) string {
 	buf.ReadFrom(reader)
 	return buf.String()
 }
+
+func TestTemplateTimings(t *testing.T) {
+	option := "template={{ .Label }} {{ .ResponseTime }} dns={{ .Timings.DNS }} ttfb={{ .Timings.FirstByte }}"
+	expected := "my label 152ms dns=3ms ttfb=100ms"
+	formatter := NewTemplateResultFormatter(option, emptyModifiersForTesting)
+	result := NewResult(true, nil, "hello", "hello", "http://hello/world", "my label")
+	result.ResponseTime = testTimings.Total
+	result.Timings = testTimings
+	reader := formatter.Reader(result)
+	assert.Equal(t, expected, readerToString(reader))
+}
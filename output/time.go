@@ -24,3 +24,29 @@ func NewPrettyTimeReader(t time.Time) io.Reader {
 	timeString := fmt.Sprintf("%v%v%v\n", colorYellow, t.Format(time.RFC1123), colorReset)
 	return strings.NewReader(timeString)
 }
+
+// Duration is a time.Duration that prints and marshals to JSON rounded to the millisecond, such as "152ms".
+type Duration time.Duration
+
+func (d Duration) String() string {
+	td := time.Duration(d)
+	if td < time.Millisecond {
+		return td.Round(time.Microsecond).String()
+	}
+	return td.Round(time.Millisecond).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%q", d.String())), nil
+}
+
+// Timings breaks a response time down into the phases of the request.
+// FirstByte is the time the server took between receiving the request and sending the first byte.
+type Timings struct {
+	DNS       Duration `json:"dns"`
+	Connect   Duration `json:"connect"`
+	TLS       Duration `json:"tls"`
+	FirstByte Duration `json:"firstByte"`
+	Transfer  Duration `json:"transfer"`
+	Total     Duration `json:"total"`
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var wideTimingColumns = []string{"TOTAL", "DNS", "CONNECT", "TLS", "TTFB", "XFER"}
+
+// wideHeader builds the header for the -wide variants of the separator formats.
+// labelSuffix lets the tab format keep its extra label padding.
+func wideHeader(modifiers *ResultFormatModifiers, separator string, labelSuffix string) io.Reader {
+	columns := append([]string{"RESULT", "EXPECT", "ACTUAL"}, wideTimingColumns...)
+	columns = append(columns, "LABEL", "URL", "ASSERTION")
+
+	for i, c := range columns {
+		switch {
+		case modifiers.Markdown:
+			columns[i] = fmt.Sprintf("*%v*", c)
+		case modifiers.Pretty:
+			columns[i] = fmt.Sprintf("%v%v%v", colorYellow, c, colorReset)
+		}
+		if c == "LABEL" {
+			columns[i] += labelSuffix
+		}
+	}
+	return strings.NewReader(strings.Join(columns, separator) + "\n")
+}
+
+// Normal Result
+// +wide
+func separatorWideResult(result *Result, modifiers *ResultFormatModifiers, separator string) string {
+	var status string
+	switch {
+	case modifiers.Markdown:
+		status = fmt.Sprintf("*%v*", result.StatusMessage())
+	case modifiers.Pretty:
+		statusColor := colorForResult(result)
+		status = fmt.Sprintf("%v%v%v", statusColor, result.StatusMessage(), colorReset)
+	default:
+		status = result.StatusMessage()
+	}
+
+	columns := append([]string{status, quoteIfContains(result.Expected, separator), result.StatusCodeActual()}, timingColumns(result)...)
+	columns = append(columns, result.Label, result.Url)
+	return strings.Join(columns, separator) + assertionColumn(result, separator)
+}
+
+func timingColumns(result *Result) []string {
+	t := result.Timings
+	if t == nil {
+		columns := make([]string, len(wideTimingColumns))
+		for i := range columns {
+			columns[i] = "n/a"
+		}
+		return columns
+	}
+	return []string{t.Total.String(), t.DNS.String(), t.Connect.String(), t.TLS.String(), t.FirstByte.String(), t.Transfer.String()}
+}
@@ -1,6 +1,8 @@
 package args
 
 import (
+	"fmt"
+
 	"github.com/codegangsta/cli"
 	"github.com/mkboudreau/asrt/config"
 )
@@ -29,8 +31,33 @@ func (tc *targetFromArgs) GetTargets(c *cli.Context) ([]*config.Target, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := t.LoadBody(!c.Bool("no-environment")); err != nil {
+			return nil, err
+		}
 		t.Timeout = timeout
 		targets = append(targets, t)
 	}
 	return targets, nil
 }
+
+func (tc *targetFromArgs) ValidateTargets(c *cli.Context) ([]config.LocatedTarget, []config.Problem) {
+	var targets []config.LocatedTarget
+	var problems []config.Problem
+
+	for i, entry := range c.Args() {
+		location := config.Location{Source: fmt.Sprintf("argument %d", i+1)}
+		t, warnings, err := config.LintTarget(entry)
+		if err == nil {
+			err = t.LoadBody(!c.Bool("no-environment"))
+		}
+		if err != nil {
+			problems = append(problems, config.NewProblem(location, config.SeverityError, "%v", err))
+			continue
+		}
+		for _, warning := range warnings {
+			problems = append(problems, config.NewProblem(location, config.SeverityWarning, "%v", warning))
+		}
+		targets = append(targets, config.LocatedTarget{Location: location, Target: t})
+	}
+	return targets, problems
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -37,6 +38,13 @@ func (tc *targetFromFile) GetCommandFlags() []cli.Flag {
 	}
 }
 
+func (tc *targetFromFile) GetWatchedFiles(c *cli.Context) []string {
+	if filename := c.String("file"); filename != "" {
+		return []string{filename}
+	}
+	return nil
+}
+
 func (tc *targetFromFile) GetTargets(c *cli.Context) ([]*config.Target, error) {
 	filename := c.String("file")
 	if filename == "" {
@@ -50,11 +58,13 @@ func (tc *targetFromFile) GetTargets(c *cli.Context) ([]*config.Target, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	return tc.targetsFromReaderWithTimeout(file, timeout, turnOffEnvExpansion)
+	return tc.targetsFromReaderWithTimeout(file, timeout, turnOffEnvExpansion, filepath.Dir(filename))
 }
 
-func (tc *targetFromFile) targetsFromReaderWithTimeout(reader io.Reader, timeout time.Duration, noEnv bool) ([]*config.Target, error) {
+// targetsFromReaderWithTimeout reads @file bodies relative to dir, the directory of the target file
+func (tc *targetFromFile) targetsFromReaderWithTimeout(reader io.Reader, timeout time.Duration, noEnv bool, dir string) ([]*config.Target, error) {
 	var targets []*config.Target
 	r := bufio.NewReader(reader)
 	for {
@@ -83,6 +93,10 @@ func (tc *targetFromFile) targetsFromReaderWithTimeout(reader io.Reader, timeout
 		if tErr != nil {
 			return nil, fmt.Errorf("could not create target from string %v: %v", line, tErr)
 		}
+		t.ResolveBodyFile(dir)
+		if bErr := t.LoadBody(!noEnv); bErr != nil {
+			return nil, fmt.Errorf("could not create target from string %v: %v", line, bErr)
+		}
 
 		t.Timeout = timeout
 		targets = append(targets, t)
@@ -90,3 +104,55 @@ func (tc *targetFromFile) targetsFromReaderWithTimeout(reader io.Reader, timeout
 
 	return targets, nil
 }
+
+func (tc *targetFromFile) ValidateTargets(c *cli.Context) ([]config.LocatedTarget, []config.Problem) {
+	filename := c.String("file")
+	if filename == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, []config.Problem{config.NewProblem(config.Location{Source: filename}, config.SeverityError, "%v", err)}
+	}
+	defer file.Close()
+
+	return tc.validateReader(filename, file, c.Bool("no-environment"))
+}
+
+// validateReader parses every line like targetsFromReaderWithTimeout, but reports all problems with their line numbers
+func (tc *targetFromFile) validateReader(filename string, reader io.Reader, noEnv bool) ([]config.LocatedTarget, []config.Problem) {
+	var targets []config.LocatedTarget
+	var problems []config.Problem
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.Trim(scanner.Text(), "\n\t ")
+		if strings.HasPrefix(line, "#") || len(line) == 0 {
+			continue
+		}
+		if !noEnv {
+			line = os.ExpandEnv(line)
+		}
+
+		location := config.Location{Source: filename, Line: lineNumber}
+		t, warnings, err := config.LintTarget(line)
+		if err == nil {
+			t.ResolveBodyFile(filepath.Dir(filename))
+			err = t.LoadBody(!noEnv)
+		}
+		if err != nil {
+			problems = append(problems, config.NewProblem(location, config.SeverityError, "%v", err))
+			continue
+		}
+		for _, warning := range warnings {
+			problems = append(problems, config.NewProblem(location, config.SeverityWarning, "%v", warning))
+		}
+		targets = append(targets, config.LocatedTarget{Location: location, Target: t})
+	}
+	if err := scanner.Err(); err != nil {
+		problems = append(problems, config.NewProblem(config.Location{Source: filename}, config.SeverityError, "%v", err))
+	}
+
+	return targets, problems
+}
@@ -1,9 +1,16 @@
 package file
 
 import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/mkboudreau/asrt/config"
 )
 
 var testTimeoutDurationOneMinute = (1 * time.Minute)
@@ -16,7 +23,7 @@ func TestFileReaderWithEmptyLines(t *testing.T) {
 	`
 	testReader := strings.NewReader(testLine)
 
-	targets, err := tc.targetsFromReaderWithTimeout(testReader, testTimeoutDurationOneMinute, false)
+	targets, err := tc.targetsFromReaderWithTimeout(testReader, testTimeoutDurationOneMinute, false, "")
 
 	if err != nil {
 		t.Fail()
@@ -39,7 +46,7 @@ func TestFileReaderWithComment(t *testing.T) {
 	`
 	testReader := strings.NewReader(testLine)
 
-	targets, err := tc.targetsFromReaderWithTimeout(testReader, testTimeoutDurationOneMinute, false)
+	targets, err := tc.targetsFromReaderWithTimeout(testReader, testTimeoutDurationOneMinute, false, "")
 
 	if err != nil {
 		t.Fail()
@@ -52,4 +59,67 @@ func TestFileReaderWithComment(t *testing.T) {
 	}
 }
 
-//func (tc *targetFromFile) targetsFromReaderWithTimeout(reader io.Reader, timeout time.Duration) ([]*config.Target, error) {
+//func (tc *targetFromFile) targetsFromReaderWithTimeout(reader io.Reader, timeout time.Duration, noEnv bool, dir string) ([]*config.Target, error) {
+
+func TestFileValidateReader(t *testing.T) {
+	var tc targetFromFile
+	testLines := `# comment
+www.yahoo.com|GTE
+
+www.yahoo.com|{O}attempts=many
+www.yahoo.com|GET|200
+`
+	targets, problems := tc.validateReader("sites.list", strings.NewReader(testLines), false)
+
+	if len(targets) != 2 {
+		t.Errorf("Targets should have length 2. Found %d, %+v", len(targets), targets)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Problems should have length 2. Found %d, %+v", len(problems), problems)
+	}
+	if problems[0].Line != 2 || problems[0].Severity != config.SeverityWarning {
+		t.Errorf("Expected a warning on line 2. Found %v", problems[0])
+	}
+	if problems[1].Line != 4 || problems[1].Severity != config.SeverityError || problems[1].Source != "sites.list" {
+		t.Errorf("Expected an error on line 4. Found %v", problems[1])
+	}
+}
+
+func TestFileBodyRelativeToTargetFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asrt-targets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "order.json"), []byte(`{"id":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "sites.list")
+	if err := ioutil.WriteFile(filename, []byte("http://api.internal/orders|POST|201|{D}@order.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// started from somewhere else than the directory of the target file
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(os.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	var tc targetFromFile
+	set := flag.NewFlagSet("status", flag.ContinueOnError)
+	set.String("file", filename, "")
+	set.String("timeout", "1m", "")
+	targets, err := tc.GetTargets(cli.NewContext(cli.NewApp(), set, nil))
+	if err != nil {
+		t.Fatalf("Error is not nil %v", err)
+	}
+	if len(targets) != 1 || targets[0].Body != `{"id":1}` {
+		t.Fatalf("Expected the body of order.json next to the target file. Found %+v", targets)
+	}
+
+	_, problems := tc.ValidateTargets(cli.NewContext(cli.NewApp(), set, nil))
+	if len(problems) != 0 {
+		t.Errorf("Problems should be empty. Found %+v", problems)
+	}
+}
@@ -6,4 +6,5 @@ import (
 	_ "github.com/mkboudreau/asrt/prebuilt/http"
 	_ "github.com/mkboudreau/asrt/prebuilt/slack"
 	_ "github.com/mkboudreau/asrt/prebuilt/stdout"
+	_ "github.com/mkboudreau/asrt/prebuilt/structured"
 )
@@ -0,0 +1,189 @@
+package structured
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/mkboudreau/asrt/config"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	config.RegisterTargetConfigurer(new(targetFromStructuredFile))
+}
+
+// targetFromStructuredFile reads targets and global settings from a YAML or JSON file. JSON is read as YAML.
+type targetFromStructuredFile struct {
+}
+
+func (tc *targetFromStructuredFile) String() string {
+	return "Structured File Target Configurer"
+}
+
+func (tc *targetFromStructuredFile) GetCommandFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "config, c",
+			Usage: "Use YAML or JSON file with targets and settings, such as workers, timeout, format, rate and writers",
+			Value: "",
+		},
+	}
+}
+
+func (tc *targetFromStructuredFile) GetSettings(c *cli.Context) (map[string]string, error) {
+	file, err := tc.readFile(c)
+	if err != nil || file == nil {
+		return nil, err
+	}
+	return file.SettingsFlags(), nil
+}
+
+func (tc *targetFromStructuredFile) GetWatchedFiles(c *cli.Context) []string {
+	if filename := c.String("config"); filename != "" {
+		return []string{filename}
+	}
+	return nil
+}
+
+func (tc *targetFromStructuredFile) GetTargets(c *cli.Context) ([]*config.Target, error) {
+	file, err := tc.readFile(c)
+	if err != nil || file == nil {
+		return []*config.Target{}, err
+	}
+
+	timeout := config.GetTimeDurationConfig(c, "timeout")
+	return tc.targetsWithTimeout(file, timeout, c.Bool("no-environment"), filepath.Dir(c.String("config")))
+}
+
+func (tc *targetFromStructuredFile) readFile(c *cli.Context) (*config.StructuredFile, error) {
+	filename := c.String("config")
+	if filename == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	structured, err := tc.structuredFileFromReader(file, c.Bool("no-environment"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file %v: %v", filename, err)
+	}
+	return structured, nil
+}
+
+func (tc *targetFromStructuredFile) structuredFileFromReader(reader io.Reader, noEnv bool) (*config.StructuredFile, error) {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	content := string(b)
+	if !noEnv {
+		content = os.ExpandEnv(content)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return nil, err
+	}
+	var file config.StructuredFile
+	if err := root.Decode(&file); err != nil {
+		return nil, err
+	}
+	for i, line := range targetLines(&root) {
+		if i < len(file.Targets) {
+			file.Targets[i].Line = line
+		}
+	}
+	return &file, nil
+}
+
+// targetLines finds the line of every entry of the targets list
+func targetLines(root *yaml.Node) []int {
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var lines []int
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "targets" {
+			for _, target := range root.Content[i+1].Content {
+				lines = append(lines, target.Line)
+			}
+		}
+	}
+	return lines
+}
+
+// targetsWithTimeout gives targets without a timeout of their own the global timeout, and reads @file
+// bodies relative to dir, the directory of the config file
+func (tc *targetFromStructuredFile) targetsWithTimeout(file *config.StructuredFile, timeout time.Duration, noEnv bool, dir string) ([]*config.Target, error) {
+	var targets []*config.Target
+	for i := range file.Targets {
+		t, err := file.Targets[i].Target()
+		if err != nil {
+			return nil, fmt.Errorf("could not create target %d from config file: %v", i+1, err)
+		}
+		t.ResolveBodyFile(dir)
+		if err := t.LoadBody(!noEnv); err != nil {
+			return nil, fmt.Errorf("could not create target %d from config file: %v", i+1, err)
+		}
+		if t.Timeout == 0 {
+			t.Timeout = timeout
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func (tc *targetFromStructuredFile) ValidateTargets(c *cli.Context) ([]config.LocatedTarget, []config.Problem) {
+	filename := c.String("config")
+	if filename == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, []config.Problem{config.NewProblem(config.Location{Source: filename}, config.SeverityError, "%v", err)}
+	}
+	defer file.Close()
+
+	return tc.validateReader(filename, file, c.Bool("no-environment"))
+}
+
+// validateReader reports the problems of every target with the line the target starts on
+func (tc *targetFromStructuredFile) validateReader(filename string, reader io.Reader, noEnv bool) ([]config.LocatedTarget, []config.Problem) {
+	fileLocation := config.Location{Source: filename}
+	structured, err := tc.structuredFileFromReader(reader, noEnv)
+	if err != nil {
+		return nil, []config.Problem{config.NewProblem(fileLocation, config.SeverityError, "%v", err)}
+	}
+
+	var targets []config.LocatedTarget
+	var problems []config.Problem
+	for i := range structured.Targets {
+		location := config.Location{Source: filename, Line: structured.Targets[i].Line}
+		t, err := structured.Targets[i].Target()
+		if err == nil {
+			t.ResolveBodyFile(filepath.Dir(filename))
+			err = t.LoadBody(!noEnv)
+		}
+		if err != nil {
+			problems = append(problems, config.NewProblem(location, config.SeverityError, "%v", err))
+			continue
+		}
+		targets = append(targets, config.LocatedTarget{Location: location, Target: t})
+	}
+	return targets, problems
+}
@@ -0,0 +1,193 @@
+package structured
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/mkboudreau/asrt/config"
+)
+
+var testTimeoutDurationOneMinute = (1 * time.Minute)
+
+func TestStructuredFileFromYAML(t *testing.T) {
+	var tc targetFromStructuredFile
+	testFile := `
+workers: 5
+timeout: 10s
+format: csv
+writers:
+  slack-url: https://hooks.example.com
+targets:
+  - url: www.yahoo.com
+  - url: https://api.example.com/health
+    method: POST
+    status: 200,204
+    label: health
+    timeout: 2s
+    headers:
+      Accept: text/plain
+    assertions:
+      body: ["ok"]
+    warn-after: 500ms
+`
+	file, err := tc.structuredFileFromReader(strings.NewReader(testFile), false)
+	if err != nil {
+		t.Fatalf("Error is not nil %v", err)
+	}
+	if settings := file.SettingsFlags(); settings["workers"] != "5" || settings["format"] != "csv" || settings["slack-url"] != "https://hooks.example.com" {
+		t.Errorf("Unexpected settings %+v", settings)
+	}
+
+	targets, err := tc.targetsWithTimeout(file, testTimeoutDurationOneMinute, false, "")
+	if err != nil {
+		t.Fatalf("Error is not nil %v", err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("Targets should have length 2. Found %d, %+v", len(targets), targets)
+	}
+	if targets[0].Timeout != testTimeoutDurationOneMinute {
+		t.Errorf("Target without a timeout should use the global timeout. Found %v", targets[0].Timeout)
+	}
+
+	health := targets[1]
+	if health.Method != config.MethodPost || health.StatusExpectation().String() != "200,204" || health.Label != "health" {
+		t.Errorf("Unexpected target %+v", health)
+	}
+	if health.Timeout != 2*time.Second || health.WarnAfter != 500*time.Millisecond {
+		t.Errorf("Unexpected timeouts %v and %v", health.Timeout, health.WarnAfter)
+	}
+	if health.Headers["Accept"] != "text/plain" || len(health.Assertions) != 1 {
+		t.Errorf("Unexpected headers %v or assertions %v", health.Headers, health.Assertions)
+	}
+}
+
+func TestStructuredFileFromJSON(t *testing.T) {
+	var tc targetFromStructuredFile
+	testFile := `{"rate": "30s", "targets": [{"url": "www.yahoo.com", "status": 301, "redirects": "none"}]}`
+
+	file, err := tc.structuredFileFromReader(strings.NewReader(testFile), false)
+	if err != nil {
+		t.Fatalf("Error is not nil %v", err)
+	}
+	targets, err := tc.targetsWithTimeout(file, testTimeoutDurationOneMinute, false, "")
+	if err != nil {
+		t.Fatalf("Error is not nil %v", err)
+	}
+	if file.Rate != "30s" || len(targets) != 1 || targets[0].ExpectedStatus != 301 || targets[0].Redirects != config.RedirectNone {
+		t.Errorf("Unexpected file %+v with targets %+v", file, targets)
+	}
+}
+
+func TestStructuredFileEnvironment(t *testing.T) {
+	var tc targetFromStructuredFile
+	os.Setenv("ASRT_TEST_HOST", "www.yahoo.com")
+	defer os.Unsetenv("ASRT_TEST_HOST")
+	testFile := `targets: [{url: "$ASRT_TEST_HOST"}]`
+
+	file, err := tc.structuredFileFromReader(strings.NewReader(testFile), false)
+	if err != nil || file.Targets[0].URL != "www.yahoo.com" {
+		t.Errorf("Environment should be expanded. Found %+v, %v", file, err)
+	}
+
+	file, err = tc.structuredFileFromReader(strings.NewReader(testFile), true)
+	if err != nil || file.Targets[0].URL != "$ASRT_TEST_HOST" {
+		t.Errorf("Environment should not be expanded. Found %+v, %v", file, err)
+	}
+}
+
+func TestStructuredFileErrors(t *testing.T) {
+	var tc targetFromStructuredFile
+	for _, testFile := range []string{
+		"targets: [{url: www.yahoo.com, unknown-option: 1}]",
+		"targets: [{method: GET}]",
+	} {
+		file, err := tc.structuredFileFromReader(strings.NewReader(testFile), false)
+		if err != nil {
+			t.Fatalf("Error is not nil %v", err)
+		}
+		if _, err := tc.targetsWithTimeout(file, testTimeoutDurationOneMinute, false, ""); err == nil {
+			t.Errorf("%v should not give valid targets", testFile)
+		}
+	}
+
+	if _, err := tc.structuredFileFromReader(strings.NewReader("targets: [unclosed"), false); err == nil {
+		t.Errorf("Invalid YAML should be an error")
+	}
+}
+
+func TestStructuredFileValidateReader(t *testing.T) {
+	var tc targetFromStructuredFile
+	testFile := `workers: 2
+targets:
+  - url: www.yahoo.com
+  - url: www.yahoo.com/health
+    method: FETCH
+  - url: www.yahoo.com/search
+    attempts: many
+`
+	targets, problems := tc.validateReader("asrt.yaml", strings.NewReader(testFile), false)
+
+	if len(targets) != 1 || targets[0].Line != 3 {
+		t.Errorf("Expected one target on line 3. Found %+v", targets)
+	}
+	if len(problems) != 2 || problems[0].Line != 4 || problems[1].Line != 6 {
+		t.Errorf("Expected errors on lines 4 and 6. Found %+v", problems)
+	}
+
+	_, problems = tc.validateReader("asrt.yaml", strings.NewReader("targets: [unclosed"), false)
+	if len(problems) != 1 || problems[0].Severity != config.SeverityError {
+		t.Errorf("Expected an error for invalid YAML. Found %+v", problems)
+	}
+}
+
+func TestStructuredBodyRelativeToConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asrt-targets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "order.json"), []byte(`{"id":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "asrt.yaml")
+	testFile := `
+targets:
+  - url: http://api.internal/orders
+    method: POST
+    status: 201
+    body_file: order.json
+`
+	if err := ioutil.WriteFile(filename, []byte(testFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// started from somewhere else than the directory of the config file
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(os.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	var tc targetFromStructuredFile
+	set := flag.NewFlagSet("status", flag.ContinueOnError)
+	set.String("config", filename, "")
+	set.String("timeout", "1m", "")
+	targets, err := tc.GetTargets(cli.NewContext(cli.NewApp(), set, nil))
+	if err != nil {
+		t.Fatalf("Error is not nil %v", err)
+	}
+	if len(targets) != 1 || targets[0].Body != `{"id":1}` {
+		t.Fatalf("Expected the body of order.json next to the config file. Found %+v", targets)
+	}
+
+	_, problems := tc.ValidateTargets(cli.NewContext(cli.NewApp(), set, nil))
+	if len(problems) != 0 {
+		t.Errorf("Problems should be empty. Found %+v", problems)
+	}
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+	"github.com/mkboudreau/asrt/storage"
+)
+
+// Window is how far back a report looks.
+const Window time.Duration = 30 * 24 * time.Hour
+
+const (
+	latencyWindow     time.Duration = 24 * time.Hour
+	errorBudgetWindow               = Window
+)
+
+var ErrNoHistory = errors.New("no history for target")
+
+// Result reports on a target from its records, oldest first. Its status is that of the latest record.
+func Result(target *config.Target, records []*storage.Record, now time.Time) *output.Result {
+	result := output.NewResult(false, ErrNoHistory, strconv.Itoa(target.ExpectedStatus), "", target.URL, target.Label)
+	result.Group = target.Group
+	result.Tags = target.Tags
+	if target.AcceptStatuses != nil {
+		result.Expected = target.AcceptStatuses.String()
+	}
+
+	if len(records) > 0 {
+		last := records[len(records)-1]
+		result.Success = last.Success
+		result.Warning = last.Warning
+		result.Flapping = last.Flapping
+		result.Expected = last.Expected
+		result.Actual = last.Actual
+		result.Assertion = last.Assertion
+		result.ResponseTime = output.Duration(last.ResponseTime)
+		result.Timestamp = output.NewTimeStringForJSON(last.Time)
+		result.Error = nil
+		if last.Error != "" {
+			result.Error = errors.New(last.Error)
+		}
+	}
+
+	result.Report = Summarize(records, target.SLO, now)
+	return result
+}
+
+// Summarize computes uptime over the last hour, day, week and 30 days, latency percentiles of the last day
+// and, when the slo is above 0, the error budget left over the last 30 days.
+func Summarize(records []*storage.Record, slo float64, now time.Time) *output.Report {
+	r := &output.Report{
+		Uptime1h:   uptime(records, now.Add(-time.Hour)),
+		Uptime24h:  uptime(records, now.Add(-24*time.Hour)),
+		Uptime7d:   uptime(records, now.Add(-7*24*time.Hour)),
+		Uptime30d:  uptime(records, now.Add(-30*24*time.Hour)),
+		Latency24h: latency(records, now.Add(-latencyWindow)),
+	}
+	if slo > 0 {
+		r.ErrorBudget = errorBudget(records, slo, now.Add(-errorBudgetWindow))
+	}
+	return r
+}
+
+func uptime(records []*storage.Record, since time.Time) *output.Uptime {
+	u := &output.Uptime{}
+	for _, r := range records {
+		if r.Time.Before(since) {
+			continue
+		}
+		u.Checks++
+		if !r.Success {
+			u.Failures++
+		}
+	}
+	if u.Checks > 0 {
+		u.Percent = 100 * float64(u.Checks-u.Failures) / float64(u.Checks)
+	}
+	return u
+}
+
+// latency only looks at successful results, since failures are often timeouts or refused connections
+func latency(records []*storage.Record, since time.Time) *output.Latency {
+	var durations []time.Duration
+	for _, r := range records {
+		if r.Success && !r.Time.Before(since) {
+			durations = append(durations, r.ResponseTime)
+		}
+	}
+	if len(durations) == 0 {
+		return nil
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return &output.Latency{
+		P50: output.Duration(percentile(durations, 50)),
+		P90: output.Duration(percentile(durations, 90)),
+		P95: output.Duration(percentile(durations, 95)),
+		P99: output.Duration(percentile(durations, 99)),
+	}
+}
+
+// percentile uses the nearest rank of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func errorBudget(records []*storage.Record, slo float64, since time.Time) *output.ErrorBudget {
+	u := uptime(records, since)
+	b := &output.ErrorBudget{
+		SLO:       slo,
+		Window:    "30d",
+		Allowed:   float64(u.Checks) * (100 - slo) / 100,
+		Failures:  u.Failures,
+		Remaining: 100,
+	}
+	if b.Allowed > 0 {
+		b.Remaining = 100 * (b.Allowed - float64(b.Failures)) / b.Allowed
+	}
+	return b
+}
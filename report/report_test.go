@@ -0,0 +1,86 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/config"
+	"github.com/mkboudreau/asrt/output"
+	"github.com/mkboudreau/asrt/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var reportNow = time.Date(2016, 3, 31, 12, 0, 0, 0, time.UTC)
+
+func record(age time.Duration, success bool, responseTime time.Duration) *storage.Record {
+	return &storage.Record{Time: reportNow.Add(-age), Url: "http://a", Success: success, Expected: "200", Actual: "200", ResponseTime: responseTime}
+}
+
+func TestSummarizeUptime(t *testing.T) {
+	records := []*storage.Record{
+		record(20*24*time.Hour, false, 0),
+		record(3*24*time.Hour, false, 0),
+		record(3*24*time.Hour, true, time.Second),
+		record(2*time.Hour, true, time.Second),
+		record(30*time.Minute, false, 0),
+		record(10*time.Minute, true, time.Second),
+	}
+
+	r := Summarize(records, 0, reportNow)
+	assert.Equal(t, &output.Uptime{Percent: 50, Checks: 2, Failures: 1}, r.Uptime1h)
+	assert.Equal(t, 3, r.Uptime24h.Checks)
+	assert.InDelta(t, 66.667, r.Uptime24h.Percent, 0.001)
+	assert.Equal(t, 5, r.Uptime7d.Checks)
+	assert.Equal(t, &output.Uptime{Percent: 50, Checks: 6, Failures: 3}, r.Uptime30d)
+	assert.Nil(t, r.ErrorBudget)
+}
+
+func TestSummarizeLatency(t *testing.T) {
+	var records []*storage.Record
+	for i := 1; i <= 100; i++ {
+		records = append(records, record(time.Minute, true, time.Duration(i)*time.Millisecond))
+	}
+	records = append(records, record(time.Minute, false, time.Minute))
+	records = append(records, record(48*time.Hour, true, time.Minute))
+
+	l := Summarize(records, 0, reportNow).Latency24h
+	assert.Equal(t, output.Duration(50*time.Millisecond), l.P50)
+	assert.Equal(t, output.Duration(90*time.Millisecond), l.P90)
+	assert.Equal(t, output.Duration(95*time.Millisecond), l.P95)
+	assert.Equal(t, output.Duration(99*time.Millisecond), l.P99)
+
+	assert.Nil(t, Summarize(nil, 0, reportNow).Latency24h)
+}
+
+func TestSummarizeErrorBudget(t *testing.T) {
+	var records []*storage.Record
+	for i := 0; i < 1000; i++ {
+		records = append(records, record(time.Hour, i != 0, time.Millisecond))
+	}
+
+	b := Summarize(records, 99.5, reportNow).ErrorBudget
+	assert.Equal(t, 99.5, b.SLO)
+	assert.InDelta(t, 5, b.Allowed, 0.0001)
+	assert.Equal(t, 1, b.Failures)
+	assert.InDelta(t, 80, b.Remaining, 0.0001)
+
+	b = Summarize(records, 99.95, reportNow).ErrorBudget
+	assert.True(t, b.Remaining < 0)
+}
+
+func TestResult(t *testing.T) {
+	target, _ := config.ParseTarget("http://a|GET|200|{O}slo=99")
+
+	result := Result(target, nil, reportNow)
+	assert.False(t, result.Success)
+	assert.Equal(t, ErrNoHistory, result.Error)
+	assert.Equal(t, "n/a", result.Report.Uptime24h.String())
+
+	records := []*storage.Record{record(time.Hour, false, 0), record(time.Minute, true, time.Millisecond)}
+	result = Result(target, records, reportNow)
+	assert.True(t, result.Success)
+	assert.Nil(t, result.Error)
+	assert.Equal(t, "200", result.Actual)
+	assert.Equal(t, "50%", result.Report.Uptime24h.String())
+	assert.Equal(t, 99.0, result.Report.ErrorBudget.SLO)
+}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileStoreExtension string = ".jsonl"
+	fileStoreDayFormat        = "2006-01-02"
+)
+
+func init() {
+	Register("file", func(location *url.URL, retention time.Duration) (Store, error) {
+		return OpenFileStore(location.Path, retention)
+	})
+}
+
+type recordKey struct {
+	Url, Label string
+}
+
+// FileStore keeps one file of json records per day, in UTC, and deletes days older than its retention.
+type FileStore struct {
+	dir       string
+	retention time.Duration
+	mutex     sync.Mutex
+	day       string
+	file      *os.File
+	last      map[recordKey]*Record
+	now       func() time.Time
+}
+
+// OpenFileStore opens, or creates, the store in dir.
+func OpenFileStore(dir string, retention time.Duration) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create history directory %v: %v", dir, err)
+	}
+
+	s := &FileStore{dir: dir, retention: retention, last: make(map[recordKey]*Record), now: time.Now}
+	if err := s.prune(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStore) Save(record *Record) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	day := record.Time.UTC().Format(fileStoreDayFormat)
+	if day != s.day {
+		if err := s.openDay(day); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	s.last[recordKey{record.Url, record.Label}] = record
+	return nil
+}
+
+// openDay switches to the file of a new day, which is also when old days are pruned
+func (s *FileStore) openDay(day string) error {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(s.dir, day+fileStoreExtension), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.day = day
+	return s.prune()
+}
+
+func (s *FileStore) Query(query Query) ([]*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	days, err := s.days()
+	if err != nil {
+		return nil, err
+	}
+
+	if cutoff := s.now().Add(-s.retention); s.retention > 0 && query.Since.Before(cutoff) {
+		query.Since = cutoff
+	}
+
+	var records []*Record
+	for _, day := range days {
+		if !query.Since.IsZero() && day < query.Since.UTC().Format(fileStoreDayFormat) {
+			continue
+		}
+		if !query.Until.IsZero() && day > query.Until.UTC().Format(fileStoreDayFormat) {
+			continue
+		}
+		dayRecords, err := s.readDay(day, query)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, dayRecords...)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+// readDay skips lines it cannot parse, such as a line cut short by a crash
+func (s *FileStore) readDay(day string, query Query) ([]*Record, error) {
+	f, err := os.Open(filepath.Join(s.dir, day+fileStoreExtension))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []*Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		r := new(Record)
+		if err := json.Unmarshal(scanner.Bytes(), r); err != nil {
+			log.Printf("Skipping unreadable history record in %v: %v", f.Name(), err)
+			continue
+		}
+		if query.Matches(r) {
+			records = append(records, r)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// Last reads the history of a target only the first time it is asked for, from the newest day back to the
+// first day that has a record of it. Targets without history are remembered as such.
+func (s *FileStore) Last(url string, label string) (*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := recordKey{url, label}
+	if r, ok := s.last[key]; ok {
+		return r, nil
+	}
+
+	days, err := s.days()
+	if err != nil {
+		return nil, err
+	}
+
+	var last *Record
+	for i := len(days) - 1; i >= 0 && last == nil; i-- {
+		records, err := s.readDay(days[i], Query{Url: url})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			if r.Label == label && (last == nil || !r.Time.Before(last.Time)) {
+				last = r
+			}
+		}
+	}
+	s.last[key] = last
+	return last, nil
+}
+
+func (s *FileStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	s.day = ""
+	return err
+}
+
+// days lists the days with a file, oldest first
+func (s *FileStore) days() ([]string, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var days []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, fileStoreExtension) {
+			continue
+		}
+		day := strings.TrimSuffix(name, fileStoreExtension)
+		if _, err := time.Parse(fileStoreDayFormat, day); err == nil {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+// prune removes the files of days that ended before the retention period
+func (s *FileStore) prune() error {
+	if s.retention <= 0 {
+		return nil
+	}
+
+	days, err := s.days()
+	if err != nil {
+		return err
+	}
+
+	cutoff := s.now().Add(-s.retention).UTC().Format(fileStoreDayFormat)
+	for _, day := range days {
+		if day >= cutoff || day == s.day {
+			continue
+		}
+		log.Printf("Removing history for %v", day)
+		if err := os.Remove(filepath.Join(s.dir, day+fileStoreExtension)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mkboudreau/asrt/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func tempStoreDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "asrt-history")
+	assert.Nil(t, err)
+	return dir
+}
+
+func TestFileStoreSaveAndQuery(t *testing.T) {
+	dir := tempStoreDir(t)
+	defer os.RemoveAll(dir)
+
+	s, err := OpenFileStore(dir, 0)
+	assert.Nil(t, err)
+
+	now := time.Now()
+	result := output.NewResult(false, errors.New("connection refused"), "200", "0", "http://a", "A")
+	assert.Nil(t, s.Save(NewRecord(result, now.Add(-48*time.Hour))))
+	assert.Nil(t, s.Save(NewRecord(output.NewResult(true, nil, "200", "200", "http://a", "A"), now.Add(-time.Hour))))
+	assert.Nil(t, s.Save(NewRecord(output.NewResult(true, nil, "200", "200", "http://b", ""), now)))
+
+	records, err := s.Query(Query{})
+	assert.Nil(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "connection refused", records[0].Error)
+
+	records, err = s.Query(Query{Url: "http://a", Since: now.Add(-24 * time.Hour)})
+	assert.Nil(t, err)
+	if assert.Len(t, records, 1) {
+		assert.True(t, records[0].Success)
+	}
+
+	records, err = s.Query(Query{Until: now.Add(-24 * time.Hour)})
+	assert.Nil(t, err)
+	assert.Len(t, records, 1)
+
+	last, err := s.Last("http://a", "A")
+	assert.Nil(t, err)
+	assert.True(t, last.Success)
+	last, err = s.Last("http://c", "")
+	assert.Nil(t, err)
+	assert.Nil(t, last)
+
+	assert.Nil(t, s.Close())
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	dir := tempStoreDir(t)
+	defer os.RemoveAll(dir)
+
+	s, err := OpenFileStore(dir, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Save(NewRecord(output.NewResult(false, nil, "200", "503", "http://a", ""), time.Now())))
+	assert.Nil(t, s.Close())
+
+	// a line cut short is skipped
+	f, err := os.OpenFile(filepath.Join(dir, time.Now().UTC().Format(fileStoreDayFormat)+fileStoreExtension), os.O_APPEND|os.O_WRONLY, 0644)
+	assert.Nil(t, err)
+	f.WriteString(`{"time":"2`)
+	f.Close()
+
+	reopened, err := Open(dir, 0)
+	assert.Nil(t, err)
+	last, err := reopened.Last("http://a", "")
+	assert.Nil(t, err)
+	if assert.NotNil(t, last) {
+		assert.Equal(t, "503", last.Actual)
+	}
+	assert.Nil(t, reopened.Close())
+}
+
+func TestFileStoreLastReadsNewestDaysFirst(t *testing.T) {
+	dir := tempStoreDir(t)
+	defer os.RemoveAll(dir)
+
+	s, err := OpenFileStore(dir, 0)
+	assert.Nil(t, err)
+	now := time.Now()
+	assert.Nil(t, s.Save(NewRecord(output.NewResult(false, nil, "200", "500", "http://a", ""), now.Add(-72*time.Hour))))
+	assert.Nil(t, s.Save(NewRecord(output.NewResult(true, nil, "200", "200", "http://a", "A"), now.Add(-48*time.Hour))))
+	assert.Nil(t, s.Save(NewRecord(output.NewResult(false, nil, "200", "503", "http://a", ""), now.Add(-24*time.Hour))))
+	assert.Nil(t, s.Close())
+
+	s, err = OpenFileStore(dir, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, s.last)
+
+	last, err := s.Last("http://a", "")
+	assert.Nil(t, err)
+	if assert.NotNil(t, last) {
+		assert.Equal(t, "503", last.Actual)
+	}
+	last, err = s.Last("http://a", "A")
+	assert.Nil(t, err)
+	if assert.NotNil(t, last) {
+		assert.Equal(t, "200", last.Actual)
+	}
+	last, err = s.Last("http://b", "")
+	assert.Nil(t, err)
+	assert.Nil(t, last)
+
+	assert.Nil(t, s.Save(NewRecord(output.NewResult(true, nil, "200", "200", "http://b", ""), now)))
+	last, err = s.Last("http://b", "")
+	assert.Nil(t, err)
+	if assert.NotNil(t, last) {
+		assert.True(t, last.Success)
+	}
+	assert.Nil(t, s.Close())
+}
+
+func TestFileStoreRetention(t *testing.T) {
+	dir := tempStoreDir(t)
+	defer os.RemoveAll(dir)
+
+	s, err := OpenFileStore(dir, 0)
+	assert.Nil(t, err)
+	now := time.Now()
+	for _, age := range []time.Duration{10 * 24 * time.Hour, 3 * 24 * time.Hour, 0} {
+		assert.Nil(t, s.Save(NewRecord(output.NewResult(true, nil, "200", "200", "http://a", ""), now.Add(-age))))
+	}
+	assert.Nil(t, s.Close())
+
+	s, err = OpenFileStore(dir, 7*24*time.Hour)
+	assert.Nil(t, err)
+	records, err := s.Query(Query{})
+	assert.Nil(t, err)
+	assert.Len(t, records, 2)
+
+	days, err := s.days()
+	assert.Nil(t, err)
+	assert.Len(t, days, 2)
+	assert.Nil(t, s.Close())
+}
+
+func TestOpenUnknownScheme(t *testing.T) {
+	_, err := Open("postgres://localhost/asrt", 0)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "file")
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkboudreau/asrt/output"
+)
+
+// Record is one stored result of one target.
+type Record struct {
+	Time         time.Time     `json:"time"`
+	Url          string        `json:"url"`
+	Label        string        `json:"label,omitempty"`
+	Success      bool          `json:"ok"`
+	Warning      bool          `json:"warn,omitempty"`
+	Flapping     bool          `json:"flapping,omitempty"`
+	Expected     string        `json:"expectation,omitempty"`
+	Actual       string        `json:"actual,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Assertion    string        `json:"assertion,omitempty"`
+	ResponseTime time.Duration `json:"responseTime,omitempty"`
+}
+
+// NewRecord keeps the parts of a result that are worth keeping over time.
+func NewRecord(result *output.Result, at time.Time) *Record {
+	r := &Record{
+		Time:         at,
+		Url:          result.Url,
+		Label:        result.Label,
+		Success:      result.Success,
+		Warning:      result.Warning,
+		Flapping:     result.Flapping,
+		Expected:     result.Expected,
+		Actual:       result.Actual,
+		Assertion:    result.Assertion,
+		ResponseTime: time.Duration(result.ResponseTime),
+	}
+	if result.Error != nil {
+		r.Error = result.Error.Error()
+	}
+	return r
+}
+
+// Query selects records. Empty fields match everything.
+type Query struct {
+	Url   string
+	Label string
+	Since time.Time
+	Until time.Time
+}
+
+// Matches reports whether the record is selected by the query.
+func (q Query) Matches(r *Record) bool {
+	switch {
+	case q.Url != "" && q.Url != r.Url:
+		return false
+	case q.Label != "" && q.Label != r.Label:
+		return false
+	case !q.Since.IsZero() && r.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && r.Time.After(q.Until):
+		return false
+	}
+	return true
+}
+
+// Store keeps the history of every result. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save adds a record to the history.
+	Save(record *Record) error
+	// Query returns the matching records, oldest first.
+	Query(query Query) ([]*Record, error)
+	// Last returns the newest record of a target, or nil when there is none.
+	Last(url string, label string) (*Record, error)
+	Close() error
+}
+
+// Opener opens a store at a location such as file:///var/lib/asrt. Records older than retention may be
+// removed; a retention of 0 keeps everything.
+type Opener func(location *url.URL, retention time.Duration) (Store, error)
+
+var (
+	openers      = make(map[string]Opener)
+	openersMutex = &sync.Mutex{}
+)
+
+// Register makes a store available under a location's url scheme.
+func Register(scheme string, opener Opener) {
+	openersMutex.Lock()
+	defer openersMutex.Unlock()
+	openers[strings.ToLower(scheme)] = opener
+}
+
+// Schemes lists the registered url schemes.
+func Schemes() []string {
+	openersMutex.Lock()
+	defer openersMutex.Unlock()
+
+	var schemes []string
+	for scheme := range openers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Open opens the store for the location. A location without a scheme is a directory for the file store.
+func Open(location string, retention time.Duration) (Store, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" {
+		u = &url.URL{Scheme: "file", Path: location}
+	}
+
+	openersMutex.Lock()
+	opener, ok := openers[strings.ToLower(u.Scheme)]
+	openersMutex.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown history store %q. Valid schemes are %v", u.Scheme, Schemes())
+	}
+	return opener(u, retention)
+}